@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/netboxlabs/diode-sdk-go/diode"
+)
+
+// Input formats of entity files
+const (
+	formatAuto   = "auto"
+	formatJSON   = "json"
+	formatYAML   = "yaml"
+	formatNDJSON = "ndjson"
+)
+
+// stdinName is the file name reading entities from stdin
+const stdinName = "-"
+
+// source locates an entity in the input files, for error reports
+type source struct {
+	// File the entity was read from, - for stdin
+	file string
+
+	// Index of the entity in the file
+	index int
+}
+
+// String returns the location of the entity, e.g. devices.yaml[3]
+func (s source) String() string {
+	file := s.file
+	if file == stdinName {
+		file = "stdin"
+	}
+	return fmt.Sprintf("%s[%d]", file, s.index)
+}
+
+// readEntities reads the entities of all files, stdin if there are none
+func readEntities(files []string, format string, stdin io.Reader) ([]diode.Entity, []source, error) {
+	if len(files) == 0 {
+		files = []string{stdinName}
+	}
+
+	var entities []diode.Entity
+	var sources []source
+	for _, file := range files {
+		var data []byte
+		var err error
+		if file == stdinName {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fileFormat := format
+		if fileFormat == formatAuto {
+			fileFormat = detectFormat(file, data)
+		}
+
+		list, err := decodeEntities(data, fileFormat)
+		if err != nil {
+			name := file
+			if file == stdinName {
+				name = "stdin"
+			}
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		for i, entity := range list {
+			entities = append(entities, entity)
+			sources = append(sources, source{file: file, index: i})
+		}
+	}
+
+	return entities, sources, nil
+}
+
+// detectFormat returns the format of a file from its extension, or from its content for stdin and unknown extensions
+func detectFormat(file string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return formatJSON
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".ndjson", ".jsonl":
+		return formatNDJSON
+	}
+
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return formatJSON
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return formatNDJSON
+	default:
+		return formatYAML
+	}
+}
+
+// decodeEntities decodes a list of entities wrapped under their type, or one wrapped entity per line for NDJSON
+func decodeEntities(data []byte, format string) (diode.EntityList, error) {
+	var list diode.EntityList
+
+	switch format {
+	case formatJSON:
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+	case formatNDJSON:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+
+			var item diode.EntityList
+			if err := json.Unmarshal(append(append([]byte("["), text...), ']'), &item); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			list = append(list, item...)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,250 @@
+// Command diode ingests entities read from JSON, YAML or NDJSON files into Diode
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/netboxlabs/diode-sdk-go/diode"
+)
+
+// Environment variables providing the flag defaults, the API key is read from diode.DiodeAPIKeyEnvVarName
+const (
+	targetEnvVarName     = "DIODE_TARGET"
+	appNameEnvVarName    = "DIODE_APP_NAME"
+	appVersionEnvVarName = "DIODE_APP_VERSION"
+	streamEnvVarName     = "DIODE_STREAM"
+)
+
+// Exit codes
+const (
+	exitOK = 0
+
+	// exitError is returned when the command failed, e.g. a file could not be read or Diode could not be reached
+	exitError = 1
+
+	// exitUsage is returned for invalid arguments
+	exitUsage = 2
+
+	// exitInvalid is returned when entities are invalid or rejected by Diode
+	exitInvalid = 3
+)
+
+// defaultAppName is the producer app name used unless set by flag or environment variable
+const defaultAppName = "diode-cli"
+
+const usage = `Usage: diode <command> [flags] [file...]
+
+Commands:
+  ingest    ingest the entities of the files, or stdin, into Diode
+  validate  validate the entities of the files, or stdin, against the Diode rules offline
+  convert   print the ingest requests built from the entities of the files, or stdin, as protojson
+
+Entity files hold lists of entities wrapped under their type as JSON, YAML or NDJSON with one wrapped entity per
+line, e.g. ` + usageExample + `
+The format is detected from the file extension unless set with -format.
+
+Run diode <command> -h for the flags of a command.
+`
+
+// usageExample is the entity file of the usage, valid against the Diode rules
+const usageExample = `[{"site": {"name": "dc-1", "slug": "dc-1", "status": "active"}}]`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// config holds the flags shared by the commands
+type config struct {
+	target     string
+	apiKey     string
+	appName    string
+	appVersion string
+	stream     string
+	format     string
+	timeout    time.Duration
+	noValidate bool
+}
+
+// run runs the command of the arguments and returns its exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	command := args[0]
+	switch command {
+	case "ingest", "validate", "convert":
+	case "-h", "-help", "--help", "help":
+		_, _ = fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n\n%s", command, usage)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var cfg config
+	fs.StringVar(&cfg.format, "format", formatAuto, "format of the entity files: auto, json, yaml or ndjson")
+	if command != "validate" {
+		fs.StringVar(&cfg.stream, "stream", envOr(streamEnvVarName, "latest"), "stream name of the ingest requests (env "+streamEnvVarName+")")
+		fs.StringVar(&cfg.appName, "app-name", envOr(appNameEnvVarName, defaultAppName), "producer app name (env "+appNameEnvVarName+")")
+		fs.StringVar(&cfg.appVersion, "app-version", envOr(appVersionEnvVarName, diode.SDKVersion), "producer app version (env "+appVersionEnvVarName+")")
+	}
+	if command == "ingest" {
+		fs.StringVar(&cfg.target, "target", os.Getenv(targetEnvVarName), "Diode target, e.g. grpc://localhost:8080/diode (env "+targetEnvVarName+")")
+		fs.StringVar(&cfg.apiKey, "api-key", "", "Diode API key (env "+diode.DiodeAPIKeyEnvVarName+")")
+		fs.DurationVar(&cfg.timeout, "timeout", time.Minute, "timeout of the ingestion")
+		fs.BoolVar(&cfg.noValidate, "no-validate", false, "send the entities without validating them first")
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	switch cfg.format {
+	case formatAuto, formatJSON, formatYAML, formatNDJSON:
+	default:
+		_, _ = fmt.Fprintf(stderr, "invalid format %q\n", cfg.format)
+		return exitUsage
+	}
+
+	entities, sources, err := readEntities(fs.Args(), cfg.format, stdin)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	switch command {
+	case "ingest":
+		return ingest(ctx, cfg, entities, sources, stdout, stderr)
+	case "validate":
+		return validate(entities, sources, stdout, stderr)
+	default:
+		return convert(ctx, cfg, entities, stdout, stderr)
+	}
+}
+
+// ingest validates the entities, unless disabled, and ingests them in chunks
+func ingest(ctx context.Context, cfg config, entities []diode.Entity, sources []source, stdout io.Writer, stderr io.Writer) int {
+	if cfg.target == "" {
+		_, _ = fmt.Fprintf(stderr, "error: target is required, set -target or %s\n", targetEnvVarName)
+		return exitUsage
+	}
+
+	if !cfg.noValidate {
+		if code := reportValidation(entities, sources, stderr); code != exitOK {
+			return code
+		}
+	}
+
+	opts := []diode.ClientOption{diode.WithStream(cfg.stream), diode.WithLogOutput(stderr)}
+	if cfg.apiKey != "" {
+		opts = append(opts, diode.WithAPIKey(cfg.apiKey))
+	}
+
+	client, err := diode.NewClient(cfg.target, cfg.appName, cfg.appVersion, opts...)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	result, err := diode.NewBatcher(client).Ingest(ctx, entities)
+
+	code := exitOK
+	for _, e := range result.Errors() {
+		_, _ = fmt.Fprintf(stderr, "%s\n", e.Error())
+		code = exitInvalid
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	_, _ = fmt.Fprintf(stdout, "ingested %d entities in %d requests\n", len(entities), len(result.Chunks))
+	return code
+}
+
+// validate validates the entities offline
+func validate(entities []diode.Entity, sources []source, stdout io.Writer, stderr io.Writer) int {
+	if code := reportValidation(entities, sources, stderr); code != exitOK {
+		return code
+	}
+
+	_, _ = fmt.Fprintf(stdout, "%d entities are valid\n", len(entities))
+	return exitOK
+}
+
+// convert prints the ingest requests the entities would be sent with
+func convert(ctx context.Context, cfg config, entities []diode.Entity, stdout io.Writer, stderr io.Writer) int {
+	// the target is not dialed in dry-run mode
+	client, err := diode.NewClient("grpc://localhost", cfg.appName, cfg.appVersion,
+		diode.WithStream(cfg.stream),
+		diode.WithLogOutput(stderr),
+		diode.WithDryRun(stdout, diode.RequestFormatJSON),
+	)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if _, err := diode.NewBatcher(client).Ingest(ctx, entities); err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// reportValidation validates the entities and reports every rule violation with the location of its entity
+func reportValidation(entities []diode.Entity, sources []source, stderr io.Writer) int {
+	err := diode.Validate(entities)
+	if err == nil {
+		return exitOK
+	}
+
+	var errs diode.ValidationErrors
+	if !errors.As(err, &errs) {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	for _, e := range errs {
+		_, _ = fmt.Fprintf(stderr, "%s: invalid %s: %s\n", sources[e.Index], e.Field, e.Reason)
+	}
+	_, _ = fmt.Fprintf(stderr, "%d of %d entities are invalid\n", len(errs.Indexes()), len(entities))
+
+	return exitInvalid
+}
+
+// envOr returns the value of the environment variable, or the default value if it is not set
+func envOr(name string, defaultValue string) string {
+	if v, ok := os.LookupEnv(name); ok && v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/netboxlabs/diode-sdk-go/diode"
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+const validEntitiesYAML = `- site:
+    name: site-1
+    slug: site-1
+    status: active
+- device:
+    name: router-1
+    status: active
+    site:
+      name: site-1
+      slug: site-1
+      status: active
+    device_type:
+      model: ISR4321
+      slug: isr4321
+      manufacturer:
+        name: Cisco
+        slug: cisco
+    role:
+      name: core
+      slug: core
+      color: ff0000
+`
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestRunValidate(t *testing.T) {
+	tests := []struct {
+		desc       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			desc:       "valid yaml file",
+			args:       []string{"validate", writeFile(t, "entities.yaml", validEntitiesYAML)},
+			wantCode:   exitOK,
+			wantStdout: "2 entities are valid",
+		},
+		{
+			desc:       "usage example",
+			args:       []string{"validate", "-format", "json"},
+			stdin:      usageExample,
+			wantCode:   exitOK,
+			wantStdout: "1 entities are valid",
+		},
+		{
+			desc:     "invalid json from stdin",
+			args:     []string{"validate"},
+			stdin:    `[{"site": {"name": "site-1", "slug": "site-1", "status": "active"}}, {"site": {"name": "site-2", "slug": "site 2", "status": "active"}}]`,
+			wantCode: exitInvalid,
+			wantStderr: `stdin[1]: invalid Site.Slug: value does not match regex pattern "^[-a-zA-Z0-9_]+$"
+1 of 2 entities are invalid`,
+		},
+		{
+			desc:       "invalid ndjson file",
+			args:       []string{"validate", writeFile(t, "entities.ndjson", "{\"site\": {\"name\": \"site-1\", \"slug\": \"site-1\", \"status\": \"active\"}}\n\n{\"site\": {\"name\": \"site-2\", \"slug\": \"site-2\"}}\n")},
+			wantCode:   exitInvalid,
+			wantStderr: "entities.ndjson[1]: invalid Site.Status",
+		},
+		{
+			desc:       "malformed file",
+			args:       []string{"validate", "-format", "json"},
+			stdin:      `[{"tenant": {}}]`,
+			wantCode:   exitError,
+			wantStderr: `error: stdin: entity 0: unknown entity type "tenant"`,
+		},
+		{
+			desc:       "missing file",
+			args:       []string{"validate", filepath.Join(t.TempDir(), "missing.yaml")},
+			wantCode:   exitError,
+			wantStderr: "no such file or directory",
+		},
+		{
+			desc:       "invalid format",
+			args:       []string{"validate", "-format", "xml"},
+			wantCode:   exitUsage,
+			wantStderr: `invalid format "xml"`,
+		},
+		{
+			desc:       "unknown command",
+			args:       []string{"delete"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown command "delete"`,
+		},
+		{
+			desc:       "no command",
+			wantCode:   exitUsage,
+			wantStderr: "Usage: diode <command>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Contains(t, stdout.String(), tt.wantStdout)
+			assert.Contains(t, stderr.String(), tt.wantStderr)
+		})
+	}
+}
+
+func TestRunConvert(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"convert", "-stream", "lab", "-app-name", "inventory", "-app-version", "1.2.3"},
+		strings.NewReader(validEntitiesYAML), &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	req := &diodepb.IngestRequest{}
+	require.NoError(t, protojson.Unmarshal(stdout.Bytes(), req))
+	assert.Equal(t, "lab", req.GetStream())
+	assert.Equal(t, "inventory", req.GetProducerAppName())
+	assert.Equal(t, "1.2.3", req.GetProducerAppVersion())
+	assert.Equal(t, diode.SDKName, req.GetSdkName())
+	require.Len(t, req.GetEntities(), 2)
+	assert.Equal(t, "router-1", req.GetEntities()[1].GetDevice().GetName())
+}
+
+func TestRunIngest(t *testing.T) {
+	t.Setenv(diode.DiodeAPIKeyEnvVarName, "")
+
+	tests := []struct {
+		desc         string
+		args         func(target string) []string
+		env          map[string]string
+		stdin        string
+		responses    []diodetest.Response
+		wantCode     int
+		wantStdout   string
+		wantStderr   string
+		wantRequests int
+	}{
+		{
+			desc: "ingested",
+			args: func(target string) []string {
+				return []string{"ingest", "-target", target, "-api-key", "abcde", "-stream", "lab"}
+			},
+			stdin:        validEntitiesYAML,
+			wantCode:     exitOK,
+			wantStdout:   "ingested 2 entities in 1 requests",
+			wantRequests: 1,
+		},
+		{
+			desc: "configured by environment variables",
+			args: func(string) []string {
+				return []string{"ingest"}
+			},
+			env: map[string]string{
+				diode.DiodeAPIKeyEnvVarName: "abcde",
+				streamEnvVarName:            "lab",
+			},
+			stdin:        validEntitiesYAML,
+			wantCode:     exitOK,
+			wantRequests: 1,
+		},
+		{
+			desc: "errors reported by Diode",
+			args: func(target string) []string {
+				return []string{"ingest", "-target", target, "-api-key", "abcde"}
+			},
+			stdin:        validEntitiesYAML,
+			responses:    []diodetest.Response{{Errors: []string{"failed to reconcile device"}}},
+			wantCode:     exitInvalid,
+			wantStderr:   "failed to reconcile device",
+			wantRequests: 1,
+		},
+		{
+			desc: "invalid entities not sent",
+			args: func(target string) []string {
+				return []string{"ingest", "-target", target, "-api-key", "abcde"}
+			},
+			stdin:      `[{"site": {"name": "site-1"}}]`,
+			wantCode:   exitInvalid,
+			wantStderr: "stdin[0]: invalid Site.Slug",
+		},
+		{
+			desc: "invalid entities sent without validation",
+			args: func(target string) []string {
+				return []string{"ingest", "-target", target, "-api-key", "abcde", "-no-validate"}
+			},
+			stdin:        `[{"site": {"name": "site-1"}}]`,
+			wantCode:     exitOK,
+			wantRequests: 1,
+		},
+		{
+			desc: "wrong API key",
+			args: func(target string) []string {
+				return []string{"ingest", "-target", target, "-api-key", "fghij"}
+			},
+			stdin:      validEntitiesYAML,
+			wantCode:   exitError,
+			wantStderr: "invalid API key",
+		},
+		{
+			desc: "missing API key",
+			args: func(target string) []string {
+				return []string{"ingest", "-target", target}
+			},
+			stdin:      validEntitiesYAML,
+			wantCode:   exitError,
+			wantStderr: diode.DiodeAPIKeyEnvVarName,
+		},
+		{
+			desc: "missing target",
+			args: func(string) []string {
+				return []string{"ingest", "-api-key", "abcde"}
+			},
+			stdin:      validEntitiesYAML,
+			wantCode:   exitUsage,
+			wantStderr: "target is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t, diodetest.WithAPIKey("abcde"), diodetest.WithResponses(tt.responses...))
+
+			t.Setenv(targetEnvVarName, "")
+			if tt.env != nil {
+				t.Setenv(targetEnvVarName, server.Target())
+				for k, v := range tt.env {
+					t.Setenv(k, v)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args(server.Target()), strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Contains(t, stdout.String(), tt.wantStdout)
+			assert.Contains(t, stderr.String(), tt.wantStderr)
+			if server.AssertRequestCount(t, tt.wantRequests) && tt.wantRequests > 0 && tt.env != nil {
+				assert.Equal(t, "lab", server.Requests()[0].GetStream())
+			}
+		})
+	}
+}
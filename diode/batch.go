@@ -0,0 +1,290 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+const (
+	// DefaultBatchMaxEntities is the default maximum number of entities in a single ingest request, matching the
+	// limit enforced by the ingester service
+	DefaultBatchMaxEntities = 1000
+
+	// DefaultBatchMaxBytes is the default maximum marshalled size of the entities in a single ingest request, leaving
+	// headroom below the default gRPC message size limit of 4 MiB
+	DefaultBatchMaxBytes = 3 * 1024 * 1024
+
+	// entitiesFieldNumber is the field number of IngestRequest.Entities
+	entitiesFieldNumber = 2
+)
+
+// Batcher splits large ingests into chunks sent as separate ingest requests
+type Batcher struct {
+	// The client used to send the chunks
+	client Client
+
+	// Maximum number of entities in a chunk
+	maxEntities int
+
+	// Maximum marshalled size of the entities in a chunk
+	maxBytes int
+
+	// Maximum number of chunks sent concurrently
+	concurrency int
+
+	// The logger for the batcher
+	logger *slog.Logger
+}
+
+// BatchOption is a functional option for the Batcher
+type BatchOption func(*Batcher)
+
+// WithBatchMaxEntities sets the maximum number of entities in a chunk, DefaultBatchMaxEntities by default
+//
+// DefaultBatchMaxEntities is the limit enforced by the ingester service, larger values are capped to it with a warning
+// and values below 1 select it.
+func WithBatchMaxEntities(n int) BatchOption {
+	return func(b *Batcher) {
+		b.maxEntities = n
+	}
+}
+
+// WithBatchMaxBytes sets the maximum marshalled size of the entities in a chunk
+func WithBatchMaxBytes(n int) BatchOption {
+	return func(b *Batcher) {
+		b.maxBytes = n
+	}
+}
+
+// WithBatchConcurrency sets the maximum number of chunks sent concurrently, chunks are sent sequentially by default
+func WithBatchConcurrency(n int) BatchOption {
+	return func(b *Batcher) {
+		b.concurrency = n
+	}
+}
+
+// NewBatcher creates a new batcher sending chunks with the given client
+func NewBatcher(client Client, opts ...BatchOption) *Batcher {
+	b := &Batcher{
+		client:      client,
+		maxEntities: DefaultBatchMaxEntities,
+		maxBytes:    DefaultBatchMaxBytes,
+		concurrency: 1,
+		logger:      clientLogger(client),
+	}
+
+	for _, o := range opts {
+		o(b)
+	}
+
+	if b.maxEntities > DefaultBatchMaxEntities {
+		b.logger.Warn("Batch max entities capped to the ingester service limit", logAttrMaxEntities, b.maxEntities,
+			logAttrLimit, DefaultBatchMaxEntities)
+	}
+	if b.maxEntities < 1 || b.maxEntities > DefaultBatchMaxEntities {
+		b.maxEntities = DefaultBatchMaxEntities
+	}
+	if b.maxBytes < 1 {
+		b.maxBytes = DefaultBatchMaxBytes
+	}
+	if b.concurrency < 1 {
+		b.concurrency = 1
+	}
+
+	return b
+}
+
+// ChunkResult is the outcome of ingesting a single chunk
+type ChunkResult struct {
+	// Index of the chunk
+	Index int
+
+	// RequestID is the ID of the ingest request the chunk was sent with
+	RequestID string
+
+	// Offset is the index of the chunk's first entity in the ingested entities
+	Offset int
+
+	// Count is the number of entities in the chunk
+	Count int
+
+	// Response is the ingester service response, nil if the request failed
+	Response *diodepb.IngestResponse
+
+	// Err is the error returned by the client, nil if the request succeeded
+	Err error
+}
+
+// ChunkError is an error reported by the ingester service for a chunk
+type ChunkError struct {
+	// Chunk is the index of the chunk
+	Chunk int
+
+	// RequestID is the ID of the ingest request the chunk was sent with
+	RequestID string
+
+	// Message is the error reported in IngestResponse.Errors
+	Message string
+}
+
+// Error returns the error message prefixed with the chunk and request ID
+func (e ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d (request %s): %s", e.Chunk, e.RequestID, e.Message)
+}
+
+// BatchResult is the aggregated outcome of a batched ingest
+type BatchResult struct {
+	// Chunks holds the result of each chunk, ordered by chunk index
+	Chunks []ChunkResult
+}
+
+// Errors returns the errors reported by the ingester service for all chunks
+func (r *BatchResult) Errors() []ChunkError {
+	var errs []ChunkError
+	for _, c := range r.Chunks {
+		for _, msg := range c.Response.GetErrors() {
+			errs = append(errs, ChunkError{Chunk: c.Index, RequestID: c.RequestID, Message: msg})
+		}
+	}
+	return errs
+}
+
+// Err returns the joined errors of all failed chunks
+func (r *BatchResult) Err() error {
+	var errs []error
+	for _, c := range r.Chunks {
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("chunk %d (request %s): %w", c.Index, c.RequestID, c.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// chunk is a contiguous range of entities sent as one ingest request
+type chunk struct {
+	offset   int
+	entities []Entity
+}
+
+// Ingest splits the entities into chunks and sends each chunk as a separate ingest request
+//
+// The returned error joins the errors of all failed chunks, the result is returned regardless so that successful
+// chunks can be told apart from failed ones.
+func (b *Batcher) Ingest(ctx context.Context, entities []Entity, opts ...IngestOption) (*BatchResult, error) {
+	chunks := splitEntities(entities, b.maxEntities, b.maxBytes)
+
+	result := &BatchResult{Chunks: make([]ChunkResult, len(chunks))}
+
+	b.logger.Debug("Ingesting entities in chunks", logAttrEntityCount, len(entities), logAttrChunkCount, len(chunks))
+
+	sem := make(chan struct{}, b.concurrency)
+	var wg sync.WaitGroup
+
+	for i, c := range chunks {
+		cr := &result.Chunks[i]
+		cr.Index = i
+		cr.RequestID = uuid.NewString()
+		cr.Offset = c.offset
+		cr.Count = len(c.entities)
+
+		if err := acquire(ctx, sem); err != nil {
+			cr.Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(cr *ChunkResult, entities []Entity) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			chunkOpts := append(append([]IngestOption(nil), opts...), WithRequestID(cr.RequestID))
+			b.logger.Debug("Sending chunk", logAttrChunk, cr.Index, logAttrRequestID, cr.RequestID, logAttrEntityCount, cr.Count)
+			cr.Response, cr.Err = b.client.Ingest(ctx, entities, chunkOpts...)
+			if cr.Err != nil {
+				b.logger.Warn("Chunk failed", logAttrChunk, cr.Index, logAttrRequestID, cr.RequestID, logAttrEntityCount, cr.Count, logAttrError, cr.Err)
+			}
+		}(cr, c.entities)
+	}
+
+	wg.Wait()
+
+	return result, result.Err()
+}
+
+// acquire acquires a slot of the semaphore unless the context is done
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// splitEntities splits the entities into chunks bounded by entity count and marshalled size
+//
+// An entity larger than maxBytes is placed in a chunk of its own, no entities are split into no chunks.
+func splitEntities(entities []Entity, maxEntities int, maxBytes int) []chunk {
+	var chunks []chunk
+
+	start := 0
+	size := 0
+	for i, entity := range entities {
+		entitySize := entityWireSize(entity)
+
+		count := i - start
+		if count > 0 && (count >= maxEntities || size+entitySize > maxBytes) {
+			chunks = append(chunks, chunk{offset: start, entities: entities[start:i]})
+			start = i
+			size = 0
+		}
+		size += entitySize
+	}
+
+	if start < len(entities) {
+		chunks = append(chunks, chunk{offset: start, entities: entities[start:]})
+	}
+
+	return chunks
+}
+
+// maxTimestampFieldSize is the marshalled size of Entity.Timestamp holding the widest valid timestamp
+var maxTimestampFieldSize = func() int {
+	fd := (&diodepb.Entity{}).ProtoReflect().Descriptor().Fields().ByName("timestamp")
+	ts := &timestamppb.Timestamp{Seconds: 253402300799, Nanos: 999999999} // 9999-12-31T23:59:59.999999999Z
+	return protowire.SizeTag(fd.Number()) + protowire.SizeBytes(proto.Size(ts))
+}()
+
+// entityWireSize returns the marshalled size of the entity as an element of IngestRequest.Entities
+//
+// Entities without timestamp are sized with the widest timestamp, an upper bound of the default timestamp set by the
+// client, so that the size does not depend on the wall clock.
+func entityWireSize(entity Entity) int {
+	protoEntity := entity.ConvertToProtoEntity()
+	n := proto.Size(protoEntity)
+	if protoEntity.Timestamp == nil {
+		n += maxTimestampFieldSize
+	}
+	return protowire.SizeTag(entitiesFieldNumber) + protowire.SizeBytes(n)
+}
+
+// protoEntityWireSize returns the marshalled size of the proto entity as an element of IngestRequest.Entities
+func protoEntityWireSize(entity *diodepb.Entity) int {
+	return protowire.SizeTag(entitiesFieldNumber) + protowire.SizeBytes(proto.Size(entity))
+}
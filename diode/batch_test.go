@@ -0,0 +1,238 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+type ingestCall struct {
+	entities  []Entity
+	requestID string
+	stream    string
+}
+
+type MockClient struct {
+	mu    sync.Mutex
+	calls []ingestCall
+
+	ingestFunc func([]Entity) (*diodepb.IngestResponse, error)
+}
+
+func (c *MockClient) Close() error {
+	return nil
+}
+
+func (c *MockClient) Ingest(_ context.Context, entities []Entity, opts ...IngestOption) (*diodepb.IngestResponse, error) {
+	var o ingestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c.mu.Lock()
+	c.calls = append(c.calls, ingestCall{entities: entities, requestID: o.requestID, stream: o.stream})
+	c.mu.Unlock()
+
+	if c.ingestFunc != nil {
+		return c.ingestFunc(entities)
+	}
+	return &diodepb.IngestResponse{}, nil
+}
+
+func (c *MockClient) Calls() []ingestCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ingestCall(nil), c.calls...)
+}
+
+func sites(n int) []Entity {
+	entities := make([]Entity, n)
+	for i := range entities {
+		entities[i] = &Site{Name: String(fmt.Sprintf("site-%d", i))}
+	}
+	return entities
+}
+
+func TestSplitEntities(t *testing.T) {
+	large := &Site{Name: String(strings.Repeat("a", 200))}
+	largeSize := entityWireSize(large)
+
+	tests := []struct {
+		desc        string
+		entities    []Entity
+		maxEntities int
+		maxBytes    int
+		wantOffsets []int
+		wantCounts  []int
+	}{
+		{
+			desc:        "no entities",
+			entities:    nil,
+			maxEntities: 10,
+			maxBytes:    DefaultBatchMaxBytes,
+			wantOffsets: nil,
+			wantCounts:  nil,
+		},
+		{
+			desc:        "split by count",
+			entities:    sites(25),
+			maxEntities: 10,
+			maxBytes:    DefaultBatchMaxBytes,
+			wantOffsets: []int{0, 10, 20},
+			wantCounts:  []int{10, 10, 5},
+		},
+		{
+			desc:        "split by size",
+			entities:    []Entity{large, large, large},
+			maxEntities: 10,
+			maxBytes:    2 * largeSize,
+			wantOffsets: []int{0, 2},
+			wantCounts:  []int{2, 1},
+		},
+		{
+			desc:        "entity larger than max bytes in a chunk of its own",
+			entities:    []Entity{large, large},
+			maxEntities: 10,
+			maxBytes:    largeSize - 1,
+			wantOffsets: []int{0, 1},
+			wantCounts:  []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			chunks := splitEntities(tt.entities, tt.maxEntities, tt.maxBytes)
+			var offsets, counts []int
+			for _, c := range chunks {
+				offsets = append(offsets, c.offset)
+				counts = append(counts, len(c.entities))
+			}
+			assert.Equal(t, tt.wantOffsets, offsets)
+			assert.Equal(t, tt.wantCounts, counts)
+		})
+	}
+}
+
+func TestEntityWireSize(t *testing.T) {
+	site := &Site{Name: String("dc1")}
+	want := entityWireSize(site)
+	for i := 0; i < 100; i++ {
+		require.Equal(t, want, entityWireSize(site))
+	}
+
+	stamped := site.ConvertToProtoEntity()
+	stamped.Timestamp = timestamppb.Now()
+	assert.GreaterOrEqual(t, want, protoEntityWireSize(stamped))
+	assert.Nil(t, site.ConvertToProtoEntity().Timestamp)
+}
+
+func TestBatcherIngest(t *testing.T) {
+	tests := []struct {
+		desc        string
+		opts        []BatchOption
+		entities    int
+		wantChunks  int
+		concurrency int
+	}{
+		{
+			desc:       "single chunk",
+			entities:   5,
+			wantChunks: 1,
+		},
+		{
+			desc:       "sequential chunks",
+			opts:       []BatchOption{WithBatchMaxEntities(2)},
+			entities:   5,
+			wantChunks: 3,
+		},
+		{
+			desc:       "concurrent chunks",
+			opts:       []BatchOption{WithBatchMaxEntities(2), WithBatchConcurrency(3)},
+			entities:   11,
+			wantChunks: 6,
+		},
+		{
+			desc:       "max entities capped to the ingester service limit",
+			opts:       []BatchOption{WithBatchMaxEntities(DefaultBatchMaxEntities + 1)},
+			entities:   DefaultBatchMaxEntities + 1,
+			wantChunks: 2,
+		},
+		{
+			desc:       "no entities",
+			entities:   0,
+			wantChunks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			client := &MockClient{}
+			entities := sites(tt.entities)
+
+			result, err := NewBatcher(client, tt.opts...).Ingest(context.Background(), entities, WithIngestStream("discovery"))
+			require.NoError(t, err)
+			require.Len(t, result.Chunks, tt.wantChunks)
+			assert.Empty(t, result.Errors())
+
+			calls := client.Calls()
+			require.Len(t, calls, tt.wantChunks)
+
+			callsByID := make(map[string]ingestCall)
+			for _, c := range calls {
+				assert.Equal(t, "discovery", c.stream)
+				callsByID[c.requestID] = c
+			}
+
+			for i, cr := range result.Chunks {
+				assert.Equal(t, i, cr.Index)
+				call, ok := callsByID[cr.RequestID]
+				require.True(t, ok)
+				assert.Equal(t, entities[cr.Offset:cr.Offset+cr.Count], call.entities)
+			}
+		})
+	}
+}
+
+func TestBatcherIngestErrors(t *testing.T) {
+	client := &MockClient{
+		ingestFunc: func(entities []Entity) (*diodepb.IngestResponse, error) {
+			switch entities[0].(*Site).GetName() {
+			case "site-2":
+				return &diodepb.IngestResponse{Errors: []string{"invalid site-2", "invalid site-3"}}, nil
+			case "site-4":
+				return nil, errors.New("unavailable")
+			}
+			return &diodepb.IngestResponse{}, nil
+		},
+	}
+
+	result, err := NewBatcher(client, WithBatchMaxEntities(2)).Ingest(context.Background(), sites(6))
+	require.Len(t, result.Chunks, 3)
+	require.EqualError(t, err, fmt.Sprintf("chunk 2 (request %s): unavailable", result.Chunks[2].RequestID))
+
+	assert.Equal(t, []ChunkError{
+		{Chunk: 1, RequestID: result.Chunks[1].RequestID, Message: "invalid site-2"},
+		{Chunk: 1, RequestID: result.Chunks[1].RequestID, Message: "invalid site-3"},
+	}, result.Errors())
+	assert.Nil(t, result.Chunks[2].Response)
+}
+
+func TestBatcherIngestCanceledContext(t *testing.T) {
+	client := &MockClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := NewBatcher(client, WithBatchMaxEntities(1)).Ingest(ctx, sites(3))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, result.Chunks, 3)
+	assert.Empty(t, client.Calls())
+}
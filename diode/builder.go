@@ -0,0 +1,21 @@
+package diode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type sets the DeviceType to a device type referenced by its manufacturer name and model
+func (b *DeviceBuilder) Type(manufacturer string, model string) *DeviceBuilder {
+	b.e.DeviceType = newDeviceTypeRef(model)
+	b.e.DeviceType.Manufacturer = newManufacturerRef(manufacturer)
+	return b
+}
+
+// missingFieldsError returns an error listing the missing required fields of an entity, nil if there are none
+func missingFieldsError(entity string, missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s is missing required fields: %s", entity, strings.Join(missing, ", "))
+}
@@ -0,0 +1,165 @@
+package diode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilders(t *testing.T) {
+	site := &Site{Name: String("dc1"), Slug: String("dc1"), Status: String("active")}
+
+	tests := []struct {
+		desc    string
+		build   func() (Entity, error)
+		want    Entity
+		wantErr string
+	}{
+		{
+			desc: "device",
+			build: func() (Entity, error) {
+				return NewDevice("sw1").Site("dc1").Type("Arista", "7050").Role("leaf").Tags("prod").Build()
+			},
+			want: &Device{
+				Name: String("sw1"),
+				Site: &Site{Name: String("dc1"), Slug: String("dc1"), Status: String("active")},
+				DeviceType: &DeviceType{
+					Model:        String("7050"),
+					Slug:         String("7050"),
+					Manufacturer: &Manufacturer{Name: String("Arista"), Slug: String("arista")},
+				},
+				Role:   &Role{Name: String("leaf"), Slug: String("leaf"), Color: String("9e9e9e")},
+				Tags:   []*Tag{{Name: String("prod"), Slug: String("prod"), Color: String("9e9e9e")}},
+				Status: String("active"),
+			},
+		},
+		{
+			desc: "site",
+			build: func() (Entity, error) {
+				return NewSite("DC 1").Build()
+			},
+			want: &Site{Name: String("DC 1"), Slug: String("dc-1"), Status: String("active")},
+		},
+		{
+			desc: "device with shared site",
+			build: func() (Entity, error) {
+				return NewDevice("sw2").SiteRef(site).Status("active").Serial("SN-2").Build()
+			},
+			want: &Device{
+				Name:   String("sw2"),
+				Site:   site,
+				Status: String("active"),
+				Serial: String("SN-2"),
+			},
+		},
+		{
+			desc: "interface with scalar fields",
+			build: func() (Entity, error) {
+				return NewInterface("eth0").Device("sw1").Type("1000base-t").Mode("access").Mtu(9000).Enabled(true).
+					MacAddress("00:11:22:33:44:55").Build()
+			},
+			want: &Interface{
+				Name:       String("eth0"),
+				Device:     &Device{Name: String("sw1"), Status: String("active")},
+				Type:       String("1000base-t"),
+				Mode:       String("access"),
+				Mtu:        Int32(9000),
+				Enabled:    Bool(true),
+				MacAddress: String("00:11:22:33:44:55"),
+			},
+		},
+		{
+			desc: "ip address assigned to an interface",
+			build: func() (Entity, error) {
+				return NewIPAddress("192.168.0.1/24").AssignedObject("eth0").Status("active").Role("vip").Build()
+			},
+			want: &IPAddress{
+				Address:        String("192.168.0.1/24"),
+				AssignedObject: &Interface{Name: String("eth0")},
+				Status:         String("active"),
+				Role:           String("vip"),
+			},
+		},
+		{
+			desc: "cluster type by name",
+			build: func() (Entity, error) {
+				return NewCluster("cluster-1").Type("VMware").Group("group-1").Status("active").Build()
+			},
+			want: &Cluster{
+				Name:   String("cluster-1"),
+				Type:   &ClusterType{Name: String("VMware"), Slug: String("vmware")},
+				Group:  &ClusterGroup{Name: String("group-1"), Slug: String("group-1")},
+				Status: String("active"),
+			},
+		},
+		{
+			desc: "missing required fields",
+			build: func() (Entity, error) {
+				return NewSite("dc1").Slug("").Build()
+			},
+			wantErr: "Site is missing required fields: Slug",
+		},
+		{
+			desc: "missing required reference",
+			build: func() (Entity, error) {
+				return NewInterface("").Build()
+			},
+			wantErr: "Interface is missing required fields: Device, Name, Type, Mode",
+		},
+		{
+			desc: "missing status",
+			build: func() (Entity, error) {
+				return NewDevice("sw1").Site("dc1").Status("").Build()
+			},
+			wantErr: "Device is missing required fields: Status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			entity, err := tt.build()
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, entity)
+		})
+	}
+}
+
+func TestBuilderBuildReturnsCopies(t *testing.T) {
+	tag := &Tag{Name: String("prod")}
+	b := NewSite("dc1").Slug("dc1").Status("active").TagRefs(tag)
+
+	first, err := b.Build()
+	require.NoError(t, err)
+
+	second, err := b.Name("dc2").Tags("edge").Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "dc1", first.GetName())
+	assert.Len(t, first.Tags, 1)
+	assert.Equal(t, "dc2", second.GetName())
+	assert.Len(t, second.Tags, 2)
+	assert.Same(t, tag, first.Tags[0])
+	assert.Same(t, tag, second.Tags[0])
+}
+
+func TestBuiltEntitiesPassValidation(t *testing.T) {
+	device, err := NewDevice("sw1").
+		Type("Arista", "7050").
+		Role("Leaf Switch").
+		Site("DC 1").
+		Platform("EOS").
+		Tags("prod", "edge").
+		Status("active").
+		Build()
+	require.NoError(t, err)
+
+	cluster, err := NewCluster("cluster-1").Type("VMware").Group("group-1").Site("DC 1").Status("active").Build()
+	require.NoError(t, err)
+
+	assert.NoError(t, Validate([]Entity{device, cluster}))
+}
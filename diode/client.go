@@ -2,22 +2,27 @@ package diode
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
 	"regexp"
 	"runtime"
-	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
 )
@@ -37,6 +42,8 @@ const (
 
 	defaultStreamName = "latest"
 
+	maxStreamNameLength = 255
+
 	authAPIKeyName = "diode-api-key"
 )
 
@@ -87,13 +94,26 @@ func getAPIKey(apiKey string) (string, error) {
 	return apiKey, nil
 }
 
+// validateStreamName checks that the stream name is accepted by the ingester service
+func validateStreamName(stream string) error {
+	if stream == "" {
+		return errors.New("stream name is required")
+	}
+
+	if utf8.RuneCountInString(stream) > maxStreamNameLength {
+		return fmt.Errorf("stream name must be at most %d characters", maxStreamNameLength)
+	}
+
+	return nil
+}
+
 // Client is an interface that defines the methods available from Diode API
 type Client interface {
 	// Close closes the connection to the API service
 	Close() error
 
 	// Ingest sends an ingest request to the ingester service
-	Ingest(context.Context, []Entity) (*diodepb.IngestResponse, error)
+	Ingest(context.Context, []Entity, ...IngestOption) (*diodepb.IngestResponse, error)
 }
 
 // GRPCClient is a gRPC implementation of the ingester service
@@ -101,12 +121,24 @@ type GRPCClient struct {
 	// The logger for the client
 	logger *slog.Logger
 
+	// Output of the default logger
+	logOutput io.Writer
+
+	// Format of the default logger
+	logFormat LogFormat
+
 	// gRPC virtual connection
 	conn *grpc.ClientConn
 
 	// The gRPC API client
 	client diodepb.IngesterServiceClient
 
+	// The gRPC API client of the client-streaming ingest RPC
+	streamClient diodepb.IngesterServiceStreamClient
+
+	// Whether the server replied Unimplemented to the client-streaming ingest RPC
+	streamingUnsupported atomic.Bool
+
 	// Producer's application name
 	appName string
 
@@ -116,15 +148,66 @@ type GRPCClient struct {
 	// An API key for the Diode API
 	apiKey string
 
+	// Provider of the API key, called for every request
+	credentials CredentialsProvider
+
 	// GRPC target
 	target string
 
 	// GRPC path
 	path string
 
+	// Stream name used for ingest requests
+	stream string
+
+	// Whether entities without a discovery timestamp are stamped with the ingest time
+	defaultTimestamp bool
+
+	// Normalizers applied to the entities before validating them
+	normalizers []Normalizer
+
+	// Whether entities with the same identity are merged before validating them
+	deduplicate bool
+
+	// How conflicting values of merged entities are resolved
+	mergePolicy MergePolicy
+
+	// Clock used for default discovery timestamps
+	now func() time.Time
+
+	// Retry policy for failed ingest requests
+	retryPolicy RetryPolicy
+
+	// How entities are validated before sending them
+	validationMode ValidationMode
+
+	// Recorder of ingest requests in dry-run mode, nil if requests are sent
+	recorder *requestRecorder
+
+	// Spool settings, nil if spooling is disabled
+	spoolConfig *spoolConfig
+
+	// Spool persisting ingest requests until they have been sent
+	spool *spool
+
+	// Cancels the replay of spooled requests
+	replayCancel context.CancelFunc
+
+	// Closed when the replay of spooled requests has finished
+	replayDone chan struct{}
+
 	// TLS verify
 	tlsVerify bool
 
+	// TLS settings for secure channels
+	tlsOptions tlsOptions
+
+	// OpenTelemetry providers
+	telemetryOptions telemetryOptions
+
+	// Spans and metrics of ingest requests
+	telemetry *telemetry
+
 	// Platform name
 	platform string
 
@@ -138,17 +221,59 @@ type GRPCClient struct {
 // ClientOption is a functional option for the GRPCClient
 type ClientOption func(*GRPCClient)
 
-// WithAPIKey sets the API key for the client
+// WithAPIKey sets a static API key for the client, taking precedence over WithCredentialsProvider
 func WithAPIKey(apiKey string) ClientOption {
 	return func(c *GRPCClient) {
 		c.apiKey = apiKey
 	}
 }
 
+// WithStream sets the stream name used for ingest requests, "latest" is used by default
+func WithStream(stream string) ClientOption {
+	return func(c *GRPCClient) {
+		c.stream = stream
+	}
+}
+
+// WithDefaultTimestamp sets whether entities without a discovery timestamp are stamped with the time of the Ingest
+// call, enabled by default
+func WithDefaultTimestamp(enabled bool) ClientOption {
+	return func(c *GRPCClient) {
+		c.defaultTimestamp = enabled
+	}
+}
+
+// IngestOption is a functional option for a single Ingest call
+type IngestOption func(*ingestOptions)
+
+// ingestOptions holds the per-call ingest settings
+type ingestOptions struct {
+	// Stream name overriding the client's stream
+	stream string
+
+	// Request ID overriding the generated one
+	requestID string
+
+	// Set to the validation errors of the skipped entities, nil if not requested
+	skipped *ValidationErrors
+}
+
+// WithIngestStream overrides the client's stream name for a single Ingest call
+func WithIngestStream(stream string) IngestOption {
+	return func(o *ingestOptions) {
+		o.stream = stream
+	}
+}
+
+// WithRequestID sets the ID of the ingest request instead of generating a new one, it must be a valid UUID
+func WithRequestID(id string) IngestOption {
+	return func(o *ingestOptions) {
+		o.requestID = id
+	}
+}
+
 // NewClient creates a new diode client based on gRPC
 func NewClient(target string, appName string, appVersion string, opts ...ClientOption) (Client, error) {
-	logger := newLogger()
-
 	if appName == "" {
 		return nil, fmt.Errorf("app name is required")
 	}
@@ -162,64 +287,180 @@ func NewClient(target string, appName string, appVersion string, opts ...ClientO
 		return nil, err
 	}
 
+	platform := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)
+	goVersion := runtime.Version()
+
+	c := &GRPCClient{
+		appName:          appName,
+		appVersion:       appVersion,
+		target:           target,
+		path:             path,
+		stream:           defaultStreamName,
+		defaultTimestamp: true,
+		now:              time.Now,
+		tlsVerify:        tlsVerify,
+		platform:         platform,
+		goVersion:        goVersion,
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	if c.logger == nil {
+		c.logger = newLogger(c.logOutput, c.logFormat)
+	}
+	logger := c.logger
+
+	if err := validateStreamName(c.stream); err != nil {
+		return nil, err
+	}
+
+	if c.recorder != nil {
+		if err := c.recorder.open(); err != nil {
+			return nil, err
+		}
+		logger.Debug("Created dry-run client", logAttrStream, c.stream, logAttrFormat, c.recorder.format.String())
+		return c, nil
+	}
+
+	if c.apiKey != "" {
+		c.credentials = StaticAPIKey(c.apiKey)
+	} else if c.credentials == nil {
+		if _, err := getAPIKey(""); err != nil {
+			return nil, err
+		}
+		c.credentials = EnvAPIKey(DiodeAPIKeyEnvVarName)
+	}
+
+	c.telemetry, err = newTelemetry(c.telemetryOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	c.metadata = metadata.Pairs("platform", platform, "go-version", goVersion)
+
 	dialOpts := []grpc.DialOption{
 		grpc.WithUserAgent(userAgent()),
+		grpc.WithPerRPCCredentials(perRPCCredentials{provider: c.credentials, requireTransportSecurity: tlsVerify}),
 	}
 
 	if path != "" {
-		logger.Debug("Setting up gRPC interceptor for path", "path", path)
-		dialOpts = append(dialOpts, methodUnaryInterceptor(path))
+		logger.Debug("Setting up gRPC interceptor for path", logAttrPath, path)
+		dialOpts = append(dialOpts, methodUnaryInterceptor(path), methodStreamInterceptor(path))
 	}
 
 	if tlsVerify {
 		logger.Debug("Setting up gRPC secure channel")
-		rootCAs := loadCerts()
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: rootCAs})))
+		tlsConfig, err := c.tlsOptions.config(logger)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	} else {
+		if c.tlsOptions.configured() {
+			return nil, errors.New("TLS options require a grpcs:// target")
+		}
 		logger.Debug("Setting up gRPC insecure channel")
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
 	conn, err := grpc.NewClient(target, dialOpts...)
 	if err != nil {
+		logger.Error("Failed to create gRPC client", logAttrTarget, target, logAttrError, err)
 		return nil, err
 	}
 
-	platform := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)
-	goVersion := runtime.Version()
+	logger.Debug("Created gRPC client", logAttrTarget, target, logAttrPath, path, logAttrTLS, tlsVerify, logAttrStream, c.stream)
 
-	c := &GRPCClient{
-		logger:     logger,
-		conn:       conn,
-		client:     diodepb.NewIngesterServiceClient(conn),
-		appName:    appName,
-		appVersion: appVersion,
-		target:     target,
-		path:       path,
-		tlsVerify:  tlsVerify,
-		platform:   platform,
-		goVersion:  goVersion,
+	c.conn = conn
+	c.client = diodepb.NewIngesterServiceClient(conn)
+	c.streamClient = diodepb.NewIngesterServiceStreamClient(conn)
+
+	if c.spoolConfig != nil {
+		if err := c.startSpool(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
 	}
 
-	var apiKey string
+	return c, nil
+}
 
-	for _, o := range opts {
-		o(c)
+// startSpool creates the spool and starts replaying the requests left over by a previous client
+func (g *GRPCClient) startSpool() error {
+	s, err := newSpool(g.spoolConfig, g.logger)
+	if err != nil {
+		return err
 	}
 
-	apiKey, err = getAPIKey(c.apiKey)
+	paths, err := s.pending()
 	if err != nil {
-		return nil, err
+		_ = s.close()
+		return fmt.Errorf("failed to read spool directory: %w", err)
 	}
 
-	c.apiKey = apiKey
-	c.metadata = metadata.Pairs(authAPIKeyName, c.apiKey, "platform", platform, "go-version", goVersion)
+	g.spool = s
 
-	return c, nil
+	ctx, cancel := context.WithCancel(context.Background())
+	g.replayCancel = cancel
+	g.replayDone = make(chan struct{})
+
+	go g.replaySpool(ctx, paths)
+
+	return nil
+}
+
+// replaySpool sends the spooled requests in order, stopping at the first transient failure to keep the remaining ones
+//
+// Requests rejected by the ingester service are moved aside and the replay continues with the next ones.
+func (g *GRPCClient) replaySpool(ctx context.Context, paths []string) {
+	defer close(g.replayDone)
+
+	if len(paths) > 0 {
+		g.logger.Info("Replaying spooled ingest requests", logAttrRequestCount, len(paths))
+	}
+
+	for _, path := range paths {
+		req, err := g.spool.read(path)
+		if err != nil {
+			g.logger.Warn("Dropping unreadable spooled request", logAttrSpoolFile, path, logAttrError, err)
+			_ = g.spool.remove(path)
+			continue
+		}
+
+		if _, err := g.send(ctx, req); err != nil {
+			if spoolable(err) {
+				g.logger.Warn("Failed to replay spooled request, keeping it for the next client", requestLogAttrs(req, logAttrError, err)...)
+				return
+			}
+			g.logger.Error("Spooled request rejected, moving it aside", requestLogAttrs(req, logAttrSpoolFile, path, logAttrError, err)...)
+			if err := g.spool.reject(path); err != nil {
+				g.logger.Warn("Failed to move rejected request aside", requestLogAttrs(req, logAttrError, err)...)
+			}
+			continue
+		}
+
+		if err := g.spool.remove(path); err != nil {
+			g.logger.Warn("Failed to remove replayed request from spool", requestLogAttrs(req, logAttrError, err)...)
+		}
+		g.logger.Debug("Replayed spooled ingest request", requestLogAttrs(req)...)
+	}
 }
 
 // Close closes the connection to the API service
 func (g *GRPCClient) Close() error {
+	if g.replayCancel != nil {
+		g.replayCancel()
+		<-g.replayDone
+	}
+
+	if g.spool != nil {
+		if err := g.spool.close(); err != nil {
+			g.logger.Warn("Failed to release spool directory lock", logAttrError, err)
+		}
+	}
+
 	if g.conn != nil {
 		return g.conn.Close()
 	}
@@ -227,27 +468,199 @@ func (g *GRPCClient) Close() error {
 }
 
 // Ingest sends an ingest request to the ingester service
-func (g *GRPCClient) Ingest(ctx context.Context, entities []Entity) (*diodepb.IngestResponse, error) {
-	stream := defaultStreamName
+func (g *GRPCClient) Ingest(ctx context.Context, entities []Entity, opts ...IngestOption) (*diodepb.IngestResponse, error) {
+	o, err := g.ingestOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	protoEntities, validationErrs, err := g.prepareEntities(entities, o)
+	if err != nil {
+		return nil, err
+	}
+	if len(protoEntities) == 0 && len(validationErrs) > 0 {
+		return &diodepb.IngestResponse{Errors: validationErrs.messages()}, nil
+	}
+
+	resp, err := g.ingestRequest(ctx, g.newRequest(o.requestID, o.stream, protoEntities))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(validationErrs) > 0 {
+		resp.Errors = append(validationErrs.messages(), resp.GetErrors()...)
+	}
+
+	return resp, nil
+}
+
+// ingestOptions applies the options of an Ingest call, generating a request ID unless one is set
+func (g *GRPCClient) ingestOptions(opts []IngestOption) (ingestOptions, error) {
+	o := ingestOptions{stream: g.stream}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := validateStreamName(o.stream); err != nil {
+		return o, err
+	}
+
+	if o.requestID == "" {
+		o.requestID = uuid.NewString()
+	} else if _, err := uuid.Parse(o.requestID); err != nil {
+		return o, fmt.Errorf("invalid request ID %q: %w", o.requestID, err)
+	}
+
+	return o, nil
+}
+
+// prepareEntities converts, normalizes, timestamps, deduplicates and validates the entities of an Ingest call
+//
+// The invalid entities are skipped and returned as validation errors with ValidationSkipInvalid, or returned as error
+// with ValidationFailFast.
+func (g *GRPCClient) prepareEntities(entities []Entity, o ingestOptions) ([]*diodepb.Entity, ValidationErrors, error) {
+	now := g.now()
 
 	protoEntities := make([]*diodepb.Entity, 0)
 	for _, entity := range entities {
-		protoEntities = append(protoEntities, entity.ConvertToProtoEntity())
+		protoEntity := entity.ConvertToProtoEntity()
+		if len(g.normalizers) > 0 {
+			normalize(protoEntity.ProtoReflect(), g.normalizers)
+		}
+		if protoEntity.Timestamp == nil && g.defaultTimestamp {
+			protoEntity.Timestamp = timestamppb.New(now)
+		}
+		protoEntities = append(protoEntities, protoEntity)
+	}
+
+	// indexes of the deduplicated entities in the given entities, nil if not deduplicated
+	var indexes []int
+	if g.deduplicate {
+		var err error
+		if protoEntities, indexes, err = deduplicateProtoEntities(protoEntities, g.mergePolicy); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	req := &diodepb.IngestRequest{
-		Id:                 uuid.NewString(),
-		Entities:           protoEntities,
+	var validationErrs ValidationErrors
+	if g.validationMode != ValidationOff {
+		validationErrs = validateProtoEntities(protoEntities, g.validationMode == ValidationFailFast)
+		if len(validationErrs) > 0 {
+			invalid := validationErrs.Indexes()
+			if indexes != nil {
+				// report the index of the first of the merged entities in the given entities
+				for _, err := range validationErrs {
+					err.Index = indexes[err.Index]
+				}
+			}
+
+			if g.validationMode == ValidationFailFast {
+				return nil, nil, validationErrs
+			}
+
+			g.logger.Warn("Skipping invalid entities", logAttrRequestID, o.requestID, logAttrStream, o.stream, logAttrEntityCount, len(invalid))
+			protoEntities = skipEntities(protoEntities, invalid)
+		}
+	}
+	if o.skipped != nil {
+		*o.skipped = validationErrs
+	}
+
+	return protoEntities, validationErrs, nil
+}
+
+// newRequest returns an ingest request of the entities with the producer and SDK details of the client
+func (g *GRPCClient) newRequest(id string, stream string, entities []*diodepb.Entity) *diodepb.IngestRequest {
+	return &diodepb.IngestRequest{
+		Id:                 id,
+		Entities:           entities,
 		Stream:             stream,
 		ProducerAppName:    g.appName,
 		ProducerAppVersion: g.appVersion,
 		SdkName:            SDKName,
 		SdkVersion:         SDKVersion,
 	}
+}
+
+// ingestRequest records the request in dry-run mode, or spools it if enabled and sends it
+func (g *GRPCClient) ingestRequest(ctx context.Context, req *diodepb.IngestRequest) (*diodepb.IngestResponse, error) {
+	if g.recorder != nil {
+		if err := g.recorder.write(req); err != nil {
+			g.logger.Error("Failed to record ingest request", requestLogAttrs(req, logAttrError, err)...)
+			return nil, err
+		}
+		g.logger.Debug("Recorded ingest request", requestLogAttrs(req)...)
+		return &diodepb.IngestResponse{}, nil
+	}
+
+	var spoolPath string
+	if g.spool != nil {
+		var err error
+		spoolPath, err = g.spool.write(req)
+		if err != nil {
+			g.logger.Error("Failed to spool ingest request", requestLogAttrs(req, logAttrError, err)...)
+		}
+	}
+
+	resp, err := g.send(ctx, req)
+	if err != nil {
+		if spoolPath != "" && !spoolable(err) {
+			g.logger.Warn("Ingest request rejected, moving it aside in the spool", requestLogAttrs(req, logAttrSpoolFile, spoolPath)...)
+			if err := g.spool.reject(spoolPath); err != nil {
+				g.logger.Warn("Failed to move rejected request aside", requestLogAttrs(req, logAttrError, err)...)
+			}
+		}
+		return nil, err
+	}
+
+	if spoolPath != "" {
+		if err := g.spool.remove(spoolPath); err != nil {
+			g.logger.Warn("Failed to remove sent request from spool", requestLogAttrs(req, logAttrError, err)...)
+		}
+	}
+
+	return resp, nil
+}
+
+// send sends the ingest request, retrying it according to the retry policy
+func (g *GRPCClient) send(ctx context.Context, req *diodepb.IngestRequest) (*diodepb.IngestResponse, error) {
+	return g.invoke(ctx, ingestMethod, []*diodepb.IngestRequest{req}, func(ctx context.Context) (*diodepb.IngestResponse, error) {
+		return g.client.Ingest(ctx, req)
+	})
+}
 
+// invoke calls an ingest RPC sending the requests, recording its telemetry and retrying it according to the retry
+// policy
+func (g *GRPCClient) invoke(ctx context.Context, method string, reqs []*diodepb.IngestRequest, call func(context.Context) (*diodepb.IngestResponse, error)) (*diodepb.IngestResponse, error) {
 	ctx = metadata.NewOutgoingContext(ctx, g.metadata)
 
-	return g.client.Ingest(ctx, req)
+	start := time.Now()
+	ctx, span := g.telemetry.start(ctx, method, reqs)
+
+	g.logger.Debug("Sending ingest request", requestsLogAttrs(reqs, logAttrMethod, method)...)
+
+	var resp *diodepb.IngestResponse
+	err := g.retryPolicy.retry(ctx, func(_ int) error {
+		var err error
+		resp, err = call(ctx)
+		return err
+	}, func(attempt int, backoff time.Duration, err error) {
+		g.telemetry.retried(span, attempt, backoff, err)
+		g.logger.Warn("Retrying ingest request", requestsLogAttrs(reqs, logAttrMethod, method, logAttrAttempt, attempt, logAttrBackoff, backoff, logAttrError, err)...)
+	})
+	g.telemetry.end(ctx, span, reqs, start, resp, err)
+	if err != nil {
+		if method == ingestStreamMethod && status.Code(err) == codes.Unimplemented {
+			g.logger.Debug("Ingest stream not supported by the server", requestsLogAttrs(reqs, logAttrError, err)...)
+		} else {
+			g.logger.Error("Ingest request failed", requestsLogAttrs(reqs, logAttrMethod, method, logAttrError, err)...)
+		}
+		return nil, err
+	}
+
+	g.logger.Debug("Ingest request sent", requestsLogAttrs(reqs, logAttrMethod, method, logAttrResponseErrorCount, len(resp.GetErrors()))...)
+
+	return resp, nil
 }
 
 // methodUnaryInterceptor returns a gRPC dial option with a unary interceptor
@@ -264,33 +677,18 @@ func methodUnaryInterceptor(path string) grpc.DialOption {
 	})
 }
 
+// methodStreamInterceptor returns a gRPC dial option with a stream interceptor
+//
+// It's the streaming equivalent of methodUnaryInterceptor, prepending the path extracted from initial target to the
+// method name of streaming calls (i.e. /diode.v1.IngesterService/IngestStream).
+func methodStreamInterceptor(path string) grpc.DialOption {
+	return grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		method = fmt.Sprintf("%s%s", path, method)
+		return streamer(ctx, desc, cc, method, opts...)
+	})
+}
+
 // userAgent returns the user agent string for the SDK
 func userAgent() string {
 	return fmt.Sprintf("%s/%s", SDKName, SDKVersion)
 }
-
-// newLogger creates a new logger for the SDK
-func newLogger() *slog.Logger {
-	level, ok := os.LookupEnv(DiodeSDKLogLevelEnvVarName)
-	if !ok {
-		level = "INFO"
-	}
-
-	var l slog.Level
-	switch strings.ToUpper(level) {
-	case "DEBUG":
-		l = slog.LevelDebug
-	case "INFO":
-		l = slog.LevelInfo
-	case "WARN":
-		l = slog.LevelWarn
-	case "ERROR":
-		l = slog.LevelError
-	default:
-		l = slog.LevelDebug
-	}
-
-	h := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: l, AddSource: false})
-
-	return slog.New(h)
-}
@@ -5,17 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"log/slog"
 	"net"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
 	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
 )
 
@@ -350,57 +354,205 @@ func TestMethodUnaryInterceptor(t *testing.T) {
 	}
 }
 
-func TestNewLogger(t *testing.T) {
+func TestValidateStreamName(t *testing.T) {
 	tests := []struct {
-		desc                string
-		logLevelEnvVarValue string
-		wantLogLevel        slog.Level
+		desc    string
+		stream  string
+		wantErr error
 	}{
 		{
-			desc:                "log level not provided",
-			logLevelEnvVarValue: "",
-			wantLogLevel:        slog.LevelInfo,
+			desc:    "default stream name",
+			stream:  "latest",
+			wantErr: nil,
 		},
 		{
-			desc:                "debug log level provided",
-			logLevelEnvVarValue: "debug",
-			wantLogLevel:        slog.LevelDebug,
+			desc:    "stream name with separators",
+			stream:  "discovery/dc-1_v2.0",
+			wantErr: nil,
 		},
 		{
-			desc:                "info log level provided",
-			logLevelEnvVarValue: "info",
-			wantLogLevel:        slog.LevelInfo,
+			desc:    "empty stream name",
+			stream:  "",
+			wantErr: errors.New("stream name is required"),
 		},
 		{
-			desc:                "warn log level provided",
-			logLevelEnvVarValue: "warn",
-			wantLogLevel:        slog.LevelWarn,
+			desc:    "stream name too long",
+			stream:  strings.Repeat("a", 256),
+			wantErr: errors.New("stream name must be at most 255 characters"),
 		},
 		{
-			desc:                "error log level provided",
-			logLevelEnvVarValue: "error",
-			wantLogLevel:        slog.LevelError,
+			desc:    "stream name with spaces",
+			stream:  "my stream",
+			wantErr: nil,
 		},
 		{
-			desc:                "invalid log level provided",
-			logLevelEnvVarValue: "invalid",
-			wantLogLevel:        slog.LevelDebug,
+			desc:    "stream name of 255 multibyte characters",
+			stream:  strings.Repeat("é", 255),
+			wantErr: nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.wantErr, validateStreamName(tt.stream))
+		})
+	}
+}
+
+func TestIngestStream(t *testing.T) {
+	tests := []struct {
+		desc       string
+		clientOpts []ClientOption
+		ingestOpts []IngestOption
+		wantStream string
+		wantErr    error
+	}{
+		{
+			desc:       "default stream",
+			wantStream: "latest",
+		},
+		{
+			desc:       "client stream",
+			clientOpts: []ClientOption{WithStream("discovery")},
+			wantStream: "discovery",
+		},
+		{
+			desc:       "per-call stream overrides client stream",
+			clientOpts: []ClientOption{WithStream("discovery")},
+			ingestOpts: []IngestOption{WithIngestStream("inventory")},
+			wantStream: "inventory",
+		},
+		{
+			desc:       "invalid per-call stream",
+			ingestOpts: []IngestOption{WithIngestStream("")},
+			wantErr:    errors.New("stream name is required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			opts := append([]ClientOption{WithAPIKey("abcde")}, tt.clientOpts...)
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", opts...)
+			require.NoError(t, err)
 			defer func() {
-				_ = os.Unsetenv(DiodeSDKLogLevelEnvVarName)
+				require.NoError(t, client.Close())
+			}()
+
+			entities := []Entity{&Site{Name: String("site-1")}}
+			_, err = client.Ingest(context.Background(), entities, tt.ingestOpts...)
+			require.Equal(t, tt.wantErr, err)
+
+			requests := server.Requests()
+			if tt.wantErr != nil {
+				require.Empty(t, requests)
+				return
+			}
+			require.Len(t, requests, 1)
+			assert.Equal(t, tt.wantStream, requests[0].GetStream())
+		})
+	}
+}
+
+func TestNewClientInvalidStream(t *testing.T) {
+	client, err := NewClient("grpc://localhost:8081", "my-producer", "0.1.0", WithAPIKey("abcde"), WithStream(""))
+	require.Nil(t, client)
+	require.Equal(t, errors.New("stream name is required"), err)
+}
+
+func TestIngestTimestamp(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	discovered := now.Add(-time.Hour)
+
+	tests := []struct {
+		desc           string
+		clientOpts     []ClientOption
+		entities       []Entity
+		wantTimestamps []*timestamppb.Timestamp
+	}{
+		{
+			desc: "default timestamp enabled",
+			entities: []Entity{
+				&Site{Name: String("site-1")},
+				Timestamped(&Site{Name: String("site-2")}, discovered),
+			},
+			wantTimestamps: []*timestamppb.Timestamp{timestamppb.New(now), timestamppb.New(discovered)},
+		},
+		{
+			desc:       "default timestamp disabled",
+			clientOpts: []ClientOption{WithDefaultTimestamp(false)},
+			entities: []Entity{
+				&Site{Name: String("site-1")},
+				Timestamped(&Site{Name: String("site-2")}, discovered),
+			},
+			wantTimestamps: []*timestamppb.Timestamp{nil, timestamppb.New(discovered)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			opts := append([]ClientOption{WithAPIKey("abcde")}, tt.clientOpts...)
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", opts...)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
 			}()
+			client.(*GRPCClient).now = func() time.Time { return now }
+
+			_, err = client.Ingest(context.Background(), tt.entities)
+			require.NoError(t, err)
 
-			if tt.logLevelEnvVarValue != "" {
-				_ = os.Setenv(DiodeSDKLogLevelEnvVarName, tt.logLevelEnvVarValue)
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			require.Len(t, requests[0].GetEntities(), len(tt.wantTimestamps))
+			for i, want := range tt.wantTimestamps {
+				assert.True(t, proto.Equal(want, requests[0].GetEntities()[i].GetTimestamp()))
 			}
+		})
+	}
+}
+
+func TestIngestRequestID(t *testing.T) {
+	tests := []struct {
+		desc       string
+		ingestOpts []IngestOption
+		wantID     string
+		wantErr    error
+	}{
+		{
+			desc:       "explicit request ID",
+			ingestOpts: []IngestOption{WithRequestID("6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11")},
+			wantID:     "6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11",
+		},
+		{
+			desc:       "invalid request ID",
+			ingestOpts: []IngestOption{WithRequestID("foobar")},
+			wantErr:    errors.New(`invalid request ID "foobar": invalid UUID length: 6`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
 
-			logger := newLogger()
-			require.NotNil(t, logger)
-			assert.True(t, logger.Enabled(context.Background(), tt.wantLogLevel))
+			_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}}, tt.ingestOpts...)
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				require.Empty(t, server.Requests())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, server.Requests(), 1)
+			assert.Equal(t, tt.wantID, server.Requests()[0].GetId())
 		})
 	}
 }
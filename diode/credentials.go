@@ -0,0 +1,144 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider provides the API key authenticating requests to the Diode API
+//
+// APIKey is called for every request, so that rotated keys are picked up without recreating the client.
+type CredentialsProvider interface {
+	// APIKey returns the API key for a request
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialsFunc is an adapter to use a function as a CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+// APIKey calls f(ctx)
+func (f CredentialsFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// staticCredentials provides a fixed API key
+type staticCredentials string
+
+// StaticAPIKey returns a provider of a fixed API key
+func StaticAPIKey(apiKey string) CredentialsProvider {
+	return staticCredentials(apiKey)
+}
+
+// APIKey returns the fixed API key
+func (c staticCredentials) APIKey(context.Context) (string, error) {
+	if c == "" {
+		return "", errors.New("API key is empty")
+	}
+	return string(c), nil
+}
+
+// envCredentials provides the API key from an environment variable
+type envCredentials string
+
+// EnvAPIKey returns a provider reading the API key from the given environment variable on every request
+func EnvAPIKey(name string) CredentialsProvider {
+	return envCredentials(name)
+}
+
+// APIKey returns the value of the environment variable
+func (c envCredentials) APIKey(context.Context) (string, error) {
+	apiKey := os.Getenv(string(c))
+	if apiKey == "" {
+		return "", fmt.Errorf("%s environment variable is not set", string(c))
+	}
+	return apiKey, nil
+}
+
+// fileCredentials provides the API key from a file, re-read when the file changes
+type fileCredentials struct {
+	// Path of the file holding the API key
+	path string
+
+	// Guards the fields below
+	mu sync.Mutex
+
+	// API key read from the file
+	apiKey string
+
+	// Modification time and size of the file when it was read
+	modTime time.Time
+	size    int64
+}
+
+// FileAPIKey returns a provider reading the API key from the given file, re-read whenever the file changes
+//
+// Leading and trailing whitespace of the file content is ignored.
+func FileAPIKey(path string) CredentialsProvider {
+	return &fileCredentials{path: path}
+}
+
+// APIKey returns the API key read from the file
+func (c *fileCredentials) APIKey(context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	if c.apiKey != "" && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return c.apiKey, nil
+	}
+
+	content, err := os.ReadFile(c.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	apiKey := strings.TrimSpace(string(content))
+	if apiKey == "" {
+		return "", fmt.Errorf("API key file %s is empty", c.path)
+	}
+
+	c.apiKey = apiKey
+	c.modTime = info.ModTime()
+	c.size = info.Size()
+
+	return c.apiKey, nil
+}
+
+// WithCredentialsProvider sets the provider of the API key, called for every request
+func WithCredentialsProvider(provider CredentialsProvider) ClientOption {
+	return func(c *GRPCClient) {
+		c.credentials = provider
+	}
+}
+
+// perRPCCredentials attaches the API key of a provider to every gRPC call
+type perRPCCredentials struct {
+	// Provider of the API key
+	provider CredentialsProvider
+
+	// Whether the credentials may only be sent over secure channels
+	requireTransportSecurity bool
+}
+
+// GetRequestMetadata returns the API key metadata for a gRPC call
+func (c perRPCCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	apiKey, err := c.provider.APIKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{authAPIKeyName: apiKey}, nil
+}
+
+// RequireTransportSecurity reports whether the credentials may only be sent over secure channels
+func (c perRPCCredentials) RequireTransportSecurity() bool {
+	return c.requireTransportSecurity
+}
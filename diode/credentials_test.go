@@ -0,0 +1,165 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+func TestStaticAPIKey(t *testing.T) {
+	apiKey, err := StaticAPIKey("foobar").APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", apiKey)
+
+	_, err = StaticAPIKey("").APIKey(context.Background())
+	require.EqualError(t, err, "API key is empty")
+}
+
+func TestEnvAPIKey(t *testing.T) {
+	t.Setenv("TEST_DIODE_API_KEY", "foobar")
+
+	provider := EnvAPIKey("TEST_DIODE_API_KEY")
+	apiKey, err := provider.APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", apiKey)
+
+	t.Setenv("TEST_DIODE_API_KEY", "barfoo")
+	apiKey, err = provider.APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "barfoo", apiKey)
+
+	_, err = EnvAPIKey("TEST_DIODE_API_KEY_UNSET").APIKey(context.Background())
+	require.EqualError(t, err, "TEST_DIODE_API_KEY_UNSET environment variable is not set")
+}
+
+func TestFileAPIKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	require.NoError(t, os.WriteFile(path, []byte("foobar\n"), 0o600))
+
+	provider := FileAPIKey(path)
+	apiKey, err := provider.APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", apiKey)
+
+	require.NoError(t, os.WriteFile(path, []byte("barfoo"), 0o600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	apiKey, err = provider.APIKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "barfoo", apiKey)
+
+	require.NoError(t, os.WriteFile(path, []byte("  \n"), 0o600))
+	_, err = provider.APIKey(context.Background())
+	require.EqualError(t, err, fmt.Sprintf("API key file %s is empty", path))
+
+	_, err = FileAPIKey(filepath.Join(t.TempDir(), "missing")).APIKey(context.Background())
+	require.Error(t, err)
+}
+
+func TestCredentialsProvider(t *testing.T) {
+	server := diodetest.Start(t)
+
+	var mu sync.Mutex
+	apiKey := "first-key"
+	provider := CredentialsFunc(func(context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return apiKey, nil
+	})
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithCredentialsProvider(provider))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+	require.NoError(t, err)
+
+	mu.Lock()
+	apiKey = "rotated-key"
+	mu.Unlock()
+
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+	require.NoError(t, err)
+
+	calls := server.Calls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"first-key"}, calls[0].Metadata.Get(authAPIKeyName))
+	assert.Equal(t, []string{"rotated-key"}, calls[1].Metadata.Get(authAPIKeyName))
+}
+
+func TestCredentialsProviderError(t *testing.T) {
+	server := diodetest.Start(t)
+
+	provider := CredentialsFunc(func(context.Context) (string, error) {
+		return "", errors.New("vault unavailable")
+	})
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithCredentialsProvider(provider))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Empty(t, server.Calls())
+}
+
+func TestAPIKeyPrecedence(t *testing.T) {
+	t.Setenv(DiodeAPIKeyEnvVarName, "env-key")
+
+	tests := []struct {
+		desc       string
+		opts       []ClientOption
+		wantAPIKey string
+	}{
+		{
+			desc:       "environment variable by default",
+			wantAPIKey: "env-key",
+		},
+		{
+			desc:       "credentials provider over environment variable",
+			opts:       []ClientOption{WithCredentialsProvider(StaticAPIKey("provider-key"))},
+			wantAPIKey: "provider-key",
+		},
+		{
+			desc:       "API key over credentials provider",
+			opts:       []ClientOption{WithCredentialsProvider(StaticAPIKey("provider-key")), WithAPIKey("explicit-key")},
+			wantAPIKey: "explicit-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t, diodetest.WithAPIKey(tt.wantAPIKey))
+
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", tt.opts...)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+			require.NoError(t, err)
+
+			calls := server.Calls()
+			require.Len(t, calls, 1)
+			assert.Equal(t, []string{tt.wantAPIKey}, calls[0].Metadata.Get(authAPIKeyName))
+		})
+	}
+}
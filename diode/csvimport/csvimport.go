@@ -0,0 +1,380 @@
+// Package csvimport imports entities from CSV files, mapping each column to a field of the entities
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/netboxlabs/diode-sdk-go/diode"
+)
+
+// defaultTagSeparator separates the tag names of a tags column unless set with WithTagSeparator
+const defaultTagSeparator = ";"
+
+// Option configures an Importer
+type Option func(*Importer)
+
+// WithColumn maps a CSV header to a field path, e.g. "Serial Number" to serial or "Vendor" to
+// device_type.manufacturer.name, headers without a mapping are used as field paths
+func WithColumn(header string, path string) Option {
+	return func(i *Importer) {
+		i.columns[header] = path
+	}
+}
+
+// WithTagSeparator sets the separator of the tag names of tags columns, ; by default
+func WithTagSeparator(sep string) Option {
+	return func(i *Importer) {
+		i.tagSeparator = sep
+	}
+}
+
+// WithComma sets the field delimiter of the CSV input, a comma by default
+func WithComma(comma rune) Option {
+	return func(i *Importer) {
+		i.comma = comma
+	}
+}
+
+// WithIgnoreUnknownColumns ignores columns not matching a field of the entity type instead of failing the import
+func WithIgnoreUnknownColumns() Option {
+	return func(i *Importer) {
+		i.ignoreUnknown = true
+	}
+}
+
+// Importer imports entities of one type from CSV input with a header row
+//
+// Every header is a field path of the entity type, made of the JSON names of the fields separated by dots, e.g. name,
+// serial or device_type.manufacturer.name for devices. Go field names are accepted too, e.g. DeviceType.Model.
+// Nested entities are allocated as needed, empty cells leave their field unset.
+type Importer struct {
+	// Entity type of the rows, e.g. device or ip_address
+	entityType string
+
+	// Field paths by CSV header
+	columns map[string]string
+
+	tagSeparator  string
+	comma         rune
+	ignoreUnknown bool
+}
+
+// NewImporter creates an importer of entities of the type, which is the name used in entity lists, e.g. device,
+// interface or ip_address
+func NewImporter(entityType string, opts ...Option) (*Importer, error) {
+	i := &Importer{
+		entityType:   entityType,
+		columns:      make(map[string]string),
+		tagSeparator: defaultTagSeparator,
+		comma:        ',',
+	}
+
+	for _, o := range opts {
+		o(i)
+	}
+
+	entity, err := diode.NewEntity(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.tagSeparator == "" {
+		return nil, errors.New("tag separator must not be empty")
+	}
+
+	for header, path := range i.columns {
+		if _, err := resolvePath(reflect.TypeOf(entity).Elem(), path); err != nil {
+			return nil, fmt.Errorf("column %q: %w", header, err)
+		}
+	}
+
+	return i, nil
+}
+
+// RowError is an invalid cell of the CSV input
+type RowError struct {
+	// Line is the line of the row in the CSV input, the header is line 1
+	Line int
+
+	// Column is the header of the invalid cell, empty if the whole row is invalid
+	Column string
+
+	// Err is the reason the cell is invalid
+	Err error
+}
+
+// Error returns the row error message
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: column %q: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the reason the cell is invalid
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors holds the errors of all invalid rows of the CSV input
+type RowErrors []*RowError
+
+// Error returns the row error messages, one per line
+func (e RowErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual row errors
+func (e RowErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Lines returns the sorted lines of the invalid rows
+func (e RowErrors) Lines() []int {
+	var lines []int
+	for _, err := range e {
+		if !slices.Contains(lines, err.Line) {
+			lines = append(lines, err.Line)
+		}
+	}
+	slices.Sort(lines)
+	return lines
+}
+
+// column is a CSV column mapped to a field
+type column struct {
+	header string
+
+	// Field indexes from the entity down to the field set by the column
+	index []int
+}
+
+// Import reads the CSV input and returns one entity per row
+//
+// Rows with invalid cells are left out and reported with RowErrors next to the entities of the valid rows, rows with
+// only empty cells are skipped. Other errors, e.g. an unknown column or malformed CSV, fail the whole import.
+func (i *Importer) Import(r io.Reader) ([]diode.Entity, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = i.comma
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entityType := reflect.TypeOf(mustNewEntity(i.entityType)).Elem()
+
+	columns := make([]*column, len(header))
+	for n, h := range header {
+		path, ok := i.columns[h]
+		if !ok {
+			path = strings.TrimSpace(h)
+		}
+		index, err := resolvePath(entityType, path)
+		if err != nil {
+			if i.ignoreUnknown && errors.Is(err, errUnknownField) {
+				continue
+			}
+			return nil, fmt.Errorf("column %q: %w", h, err)
+		}
+		columns[n] = &column{header: h, index: index}
+	}
+
+	var entities []diode.Entity
+	var rowErrs RowErrors
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+			rowErrs = append(rowErrs, &RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		entity, errs := i.importRow(line, columns, record)
+		if len(errs) > 0 {
+			rowErrs = append(rowErrs, errs...)
+			continue
+		}
+		if entity != nil {
+			entities = append(entities, entity)
+		}
+	}
+
+	if len(rowErrs) > 0 {
+		return entities, rowErrs
+	}
+	return entities, nil
+}
+
+// importRow returns the entity of a row, nil if all its cells are empty
+func (i *Importer) importRow(line int, columns []*column, record []string) (diode.Entity, RowErrors) {
+	entity := mustNewEntity(i.entityType)
+	v := reflect.ValueOf(entity).Elem()
+
+	var errs RowErrors
+	empty := true
+	for n, cell := range record {
+		cell = strings.TrimSpace(cell)
+		if columns[n] == nil || cell == "" {
+			continue
+		}
+		empty = false
+
+		if err := i.setField(v, columns[n].index, cell); err != nil {
+			errs = append(errs, &RowError{Line: line, Column: columns[n].header, Err: err})
+		}
+	}
+
+	if empty {
+		return nil, nil
+	}
+	return entity, errs
+}
+
+// setField sets the field at the index path from the cell, allocating the nested entities on the way
+func (i *Importer) setField(v reflect.Value, index []int, cell string) error {
+	for _, n := range index[:len(index)-1] {
+		f := v.Field(n)
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		v = f.Elem()
+	}
+
+	f := v.Field(index[len(index)-1])
+	switch f.Type() {
+	case stringPtrType:
+		f.Set(reflect.ValueOf(diode.String(cell)))
+	case int32PtrType:
+		n, err := strconv.ParseInt(cell, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", cell)
+		}
+		f.Set(reflect.ValueOf(diode.Int32(int32(n))))
+	case boolPtrType:
+		b, err := parseBool(cell)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(diode.Bool(b)))
+	case tagsType:
+		var tags []*diode.Tag
+		for _, name := range strings.Split(cell, i.tagSeparator) {
+			if name = strings.TrimSpace(name); name != "" {
+				tags = append(tags, &diode.Tag{Name: diode.String(name)})
+			}
+		}
+		f.Set(reflect.ValueOf(tags))
+	}
+	return nil
+}
+
+// parseBool parses true/false values as strconv.ParseBool does, and yes/no, y/n and on/off case-insensitively
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q", s)
+	}
+	return b, nil
+}
+
+// Types of the fields a column can set
+var (
+	stringPtrType = reflect.TypeOf((*string)(nil))
+	int32PtrType  = reflect.TypeOf((*int32)(nil))
+	boolPtrType   = reflect.TypeOf((*bool)(nil))
+	tagsType      = reflect.TypeOf([]*diode.Tag(nil))
+)
+
+// errUnknownField is returned when a field path does not match a field of the entity type
+var errUnknownField = errors.New("unknown field")
+
+// resolvePath returns the field indexes of the field path within the struct type
+func resolvePath(t reflect.Type, path string) ([]int, error) {
+	if path == "" {
+		return nil, errors.New("empty field path")
+	}
+
+	var index []int
+	segments := strings.Split(path, ".")
+	for n, segment := range segments {
+		f, ok := findField(t, segment)
+		if !ok {
+			return nil, fmt.Errorf("%w %q of %s", errUnknownField, segment, t.Name())
+		}
+		index = append(index, f.Index...)
+
+		last := n == len(segments)-1
+		switch {
+		case f.Type == stringPtrType, f.Type == int32PtrType, f.Type == boolPtrType, f.Type == tagsType:
+			if !last {
+				return nil, fmt.Errorf("field %q of %s has no nested fields", segment, t.Name())
+			}
+		case f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct:
+			if last {
+				return nil, fmt.Errorf("field %q of %s is an entity, map a field of it, e.g. %s.name", segment, t.Name(), path)
+			}
+			t = f.Type.Elem()
+		default:
+			return nil, fmt.Errorf("field %q of %s has unsupported type %s", segment, t.Name(), f.Type)
+		}
+	}
+
+	return index, nil
+}
+
+// findField returns the field of the struct type with the JSON name, or the Go name case-insensitively
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for n := 0; n < t.NumField(); n++ {
+		f := t.Field(n)
+		if !f.IsExported() {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if jsonName == name || strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// mustNewEntity returns a new entity of the type validated by NewImporter
+func mustNewEntity(entityType string) diode.Entity {
+	entity, err := diode.NewEntity(entityType)
+	if err != nil {
+		panic(err)
+	}
+	return entity
+}
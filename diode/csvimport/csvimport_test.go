@@ -0,0 +1,242 @@
+package csvimport_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netboxlabs/diode-sdk-go/diode"
+	"github.com/netboxlabs/diode-sdk-go/diode/csvimport"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		desc       string
+		entityType string
+		opts       []csvimport.Option
+		input      string
+		want       []diode.Entity
+		wantErr    string
+		wantLines  []int
+	}{
+		{
+			desc:       "devices with nested references and tags",
+			entityType: "device",
+			input: `name,serial,device_type.model,device_type.manufacturer.name,site.name,tags
+router-1,SN-1,ISR4321,Cisco,site-1,core; edge
+router-2,,,,site-1,
+`,
+			want: []diode.Entity{
+				&diode.Device{
+					Name:   diode.String("router-1"),
+					Serial: diode.String("SN-1"),
+					DeviceType: &diode.DeviceType{
+						Model:        diode.String("ISR4321"),
+						Manufacturer: &diode.Manufacturer{Name: diode.String("Cisco")},
+					},
+					Site: &diode.Site{Name: diode.String("site-1")},
+					Tags: []*diode.Tag{{Name: diode.String("core")}, {Name: diode.String("edge")}},
+				},
+				&diode.Device{
+					Name: diode.String("router-2"),
+					Site: &diode.Site{Name: diode.String("site-1")},
+				},
+			},
+		},
+		{
+			desc:       "go field names",
+			entityType: "device",
+			input: `Name,DeviceType.Model
+router-1,ISR4321
+`,
+			want: []diode.Entity{
+				&diode.Device{Name: diode.String("router-1"), DeviceType: &diode.DeviceType{Model: diode.String("ISR4321")}},
+			},
+		},
+		{
+			desc:       "interfaces with integer and boolean coercion",
+			entityType: "interface",
+			input: `name,device.name,mac_address,mtu,enabled,mgmt_only
+eth0,router-1,00:11:22:33:44:55,1500,true,no
+eth1,router-1,,9000,Y,0
+`,
+			want: []diode.Entity{
+				&diode.Interface{
+					Name:       diode.String("eth0"),
+					Device:     &diode.Device{Name: diode.String("router-1")},
+					MacAddress: diode.String("00:11:22:33:44:55"),
+					Mtu:        diode.Int32(1500),
+					Enabled:    diode.Bool(true),
+					MgmtOnly:   diode.Bool(false),
+				},
+				&diode.Interface{
+					Name:     diode.String("eth1"),
+					Device:   &diode.Device{Name: diode.String("router-1")},
+					Mtu:      diode.Int32(9000),
+					Enabled:  diode.Bool(true),
+					MgmtOnly: diode.Bool(false),
+				},
+			},
+		},
+		{
+			desc:       "mapped headers, custom delimiter and tag separator",
+			entityType: "site",
+			opts: []csvimport.Option{
+				csvimport.WithColumn("Site Name", "name"),
+				csvimport.WithColumn("Labels", "tags"),
+				csvimport.WithComma(';'),
+				csvimport.WithTagSeparator("|"),
+			},
+			input: `Site Name;Labels
+site-1;dc|eu
+`,
+			want: []diode.Entity{
+				&diode.Site{Name: diode.String("site-1"), Tags: []*diode.Tag{{Name: diode.String("dc")}, {Name: diode.String("eu")}}},
+			},
+		},
+		{
+			desc:       "empty rows skipped",
+			entityType: "site",
+			input: `name,slug
+site-1,site-1
+,
+`,
+			want: []diode.Entity{
+				&diode.Site{Name: diode.String("site-1"), Slug: diode.String("site-1")},
+			},
+		},
+		{
+			desc:       "ignored unknown columns",
+			entityType: "site",
+			opts:       []csvimport.Option{csvimport.WithIgnoreUnknownColumns()},
+			input: `name,owner
+site-1,alice
+`,
+			want: []diode.Entity{
+				&diode.Site{Name: diode.String("site-1")},
+			},
+		},
+		{
+			desc:       "invalid rows reported",
+			entityType: "interface",
+			input: `name,mtu,enabled
+eth0,1500,true
+eth1,jumbo,maybe
+eth2,99999999999,false
+eth3
+`,
+			want: []diode.Entity{
+				&diode.Interface{Name: diode.String("eth0"), Mtu: diode.Int32(1500), Enabled: diode.Bool(true)},
+			},
+			wantErr: `line 3: column "mtu": invalid integer "jumbo"
+line 3: column "enabled": invalid boolean "maybe"
+line 4: column "mtu": invalid integer "99999999999"
+line 5: wrong number of fields`,
+			wantLines: []int{3, 4, 5},
+		},
+		{
+			desc:       "unknown column",
+			entityType: "site",
+			input: `name,owner
+site-1,alice
+`,
+			wantErr: `column "owner": unknown field "owner" of Site`,
+		},
+		{
+			desc:       "entity column",
+			entityType: "device",
+			input: `name,site
+router-1,site-1
+`,
+			wantErr: `column "site": field "site" of Device is an entity, map a field of it, e.g. site.name`,
+		},
+		{
+			desc:       "nested field of a value",
+			entityType: "device",
+			input: `name.first
+router-1
+`,
+			wantErr: `column "name.first": field "name" of Device has no nested fields`,
+		},
+		{
+			desc:       "empty input",
+			entityType: "site",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			importer, err := csvimport.NewImporter(tt.entityType, tt.opts...)
+			require.NoError(t, err)
+
+			entities, err := importer.Import(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, entities)
+
+			if tt.wantLines != nil {
+				var rowErrs csvimport.RowErrors
+				require.True(t, errors.As(err, &rowErrs))
+				assert.Equal(t, tt.wantLines, rowErrs.Lines())
+			}
+		})
+	}
+}
+
+func TestNewImporter(t *testing.T) {
+	tests := []struct {
+		desc       string
+		entityType string
+		opts       []csvimport.Option
+		wantErr    string
+	}{
+		{
+			desc:       "valid",
+			entityType: "ip_address",
+			opts:       []csvimport.Option{csvimport.WithColumn("Interface", "assigned_object.name")},
+		},
+		{
+			desc:       "unknown entity type",
+			entityType: "tenant",
+			wantErr:    `unknown entity type "tenant"`,
+		},
+		{
+			desc:       "invalid column mapping",
+			entityType: "device",
+			opts:       []csvimport.Option{csvimport.WithColumn("Vendor", "device_type.vendor")},
+			wantErr:    `column "Vendor": unknown field "vendor" of DeviceType`,
+		},
+		{
+			desc:       "empty tag separator",
+			entityType: "device",
+			opts:       []csvimport.Option{csvimport.WithTagSeparator("")},
+			wantErr:    "tag separator must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := csvimport.NewImporter(tt.entityType, tt.opts...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestImportedEntitiesAreValid(t *testing.T) {
+	importer, err := csvimport.NewImporter("site")
+	require.NoError(t, err)
+
+	entities, err := importer.Import(strings.NewReader("name,slug,status\nsite-1,site-1,active\n"))
+	require.NoError(t, err)
+	require.NoError(t, diode.Validate(entities))
+}
@@ -0,0 +1,200 @@
+package diode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// Differ sends only the entities that are new or modified since they were last ingested successfully
+//
+// The snapshot of the last ingested version of each entity is kept in a SnapshotStore, keyed by the identity of the
+// entity and hashed on its canonical proto bytes, excluding the discovery timestamp. See IdentityOf.
+type Differ struct {
+	// The client used to send the changed entities
+	client Client
+
+	// Store of the snapshot of the ingested entities
+	store SnapshotStore
+
+	// Interval of full resyncs sending all entities, zero disables them
+	resyncInterval time.Duration
+
+	// Policy merging the entities with the same identity of an ingest
+	mergePolicy MergePolicy
+
+	// Clock used for full resyncs
+	now func() time.Time
+
+	// The logger for the differ
+	logger *slog.Logger
+
+	// Serializes ingests and snapshot updates
+	mu sync.Mutex
+}
+
+// DiffOption is a functional option for the Differ
+type DiffOption func(*Differ)
+
+// WithDiffResyncInterval sets the interval of full resyncs sending all entities regardless of the snapshot, e.g. to
+// restore objects modified or deleted in NetBox, full resyncs are disabled by default
+func WithDiffResyncInterval(d time.Duration) DiffOption {
+	return func(df *Differ) {
+		df.resyncInterval = d
+	}
+}
+
+// WithDiffMergePolicy sets the policy merging the entities with the same identity of an ingest into one entity before
+// diffing them, MergeKeepFirst by default. See Deduplicate.
+func WithDiffMergePolicy(policy MergePolicy) DiffOption {
+	return func(df *Differ) {
+		df.mergePolicy = policy
+	}
+}
+
+// NewDiffer creates a new differ sending changed entities with the given client
+func NewDiffer(client Client, store SnapshotStore, opts ...DiffOption) *Differ {
+	d := &Differ{
+		client: client,
+		store:  store,
+		now:    time.Now,
+		logger: clientLogger(client),
+	}
+
+	for _, o := range opts {
+		o(d)
+	}
+
+	return d
+}
+
+// DiffResult is the result of an ingest of the differ
+type DiffResult struct {
+	// Response is the ingester service response, nil if no entities were sent
+	Response *diodepb.IngestResponse
+
+	// Sent are the new and modified entities, or all entities on a full resync
+	Sent []Entity
+
+	// Unchanged is the number of entities not sent as they did not change
+	Unchanged int
+
+	// Removed are the identities of the entities ingested before that are missing from this ingest, sorted
+	Removed []Identity
+
+	// FullSync is whether all entities were sent as a full resync
+	FullSync bool
+}
+
+// Ingest sends the entities that are new or modified since the last ingest, or all entities when a full resync is due
+//
+// Each call is expected to carry the full inventory: entities ingested before and missing from this ingest are
+// reported as removed once. Entities with the same identity are merged into one entity by the merge policy before
+// being diffed, and fail the ingest with a MergeConflictError on conflicting values with MergeFailOnConflict. The
+// snapshot is only updated when the ingest succeeds without response errors, so that failed entities are sent again by
+// the next ingest. Entities without type are always sent.
+func (d *Differ) Ingest(ctx context.Context, entities []Entity, opts ...IngestOption) (*DiffResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	snapshot, err := d.store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	now := d.now()
+	result := &DiffResult{
+		FullSync: d.resyncInterval > 0 && now.Sub(snapshot.FullSync) >= d.resyncInterval,
+	}
+
+	next := &Snapshot{Hashes: make(map[Identity]string, len(entities)), FullSync: snapshot.FullSync}
+	if result.FullSync {
+		next.FullSync = now
+	}
+
+	protoEntities := make([]*diodepb.Entity, len(entities))
+	for i, entity := range entities {
+		protoEntities[i] = entity.ConvertToProtoEntity()
+	}
+	merged, indexes, err := deduplicateProtoEntities(protoEntities, d.mergePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range merged {
+		entity := entities[indexes[i]]
+		if m != protoEntities[indexes[i]] {
+			// merged with later entities of the same identity
+			if entity, err = EntityFromProto(m); err != nil {
+				return nil, err
+			}
+		}
+
+		id, ok := entityIdentity(m)
+		if !ok {
+			result.Sent = append(result.Sent, entity)
+			continue
+		}
+
+		hash, err := entityHash(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash entity %s: %w", id, err)
+		}
+		next.Hashes[id] = hash
+
+		if prev, ok := snapshot.Hashes[id]; ok && prev == hash && !result.FullSync {
+			result.Unchanged++
+			continue
+		}
+		result.Sent = append(result.Sent, entity)
+	}
+
+	for id := range snapshot.Hashes {
+		if _, ok := next.Hashes[id]; !ok {
+			result.Removed = append(result.Removed, id)
+		}
+	}
+	sort.Slice(result.Removed, func(i, j int) bool {
+		return result.Removed[i].String() < result.Removed[j].String()
+	})
+
+	d.logger.Debug("Diffed entities against snapshot", logAttrEntityCount, len(entities), logAttrChangedCount, len(result.Sent),
+		logAttrRemovedCount, len(result.Removed), logAttrFullSync, result.FullSync)
+
+	if len(result.Sent) > 0 {
+		result.Response, err = d.client.Ingest(ctx, result.Sent, opts...)
+		if err != nil {
+			return result, err
+		}
+		if len(result.Response.GetErrors()) > 0 {
+			d.logger.Warn("Snapshot not updated after ingest errors", logAttrResponseErrorCount, len(result.Response.GetErrors()))
+			return result, nil
+		}
+	}
+
+	if err := d.store.Save(next); err != nil {
+		return result, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return result, nil
+}
+
+// entityHash returns the hex encoded SHA-256 hash of the deterministic proto bytes of the message set in an entity
+func entityHash(entity *diodepb.Entity) (string, error) {
+	m := entity.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("entity"))
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(m.Get(fd).Message().Interface())
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
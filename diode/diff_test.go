@@ -0,0 +1,190 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestDifferIngest(t *testing.T) {
+	client := &MockClient{}
+	differ := NewDiffer(client, NewMemorySnapshotStore())
+
+	site := &Site{Name: String("dc1"), Slug: String("dc1")}
+	device := &Device{Name: String("sw1"), Site: site}
+	iface := &Interface{Name: String("eth0"), Device: device}
+
+	result, err := differ.Ingest(context.Background(), []Entity{site, device, iface})
+	require.NoError(t, err)
+	assert.Len(t, result.Sent, 3)
+	assert.Zero(t, result.Unchanged)
+	assert.Empty(t, result.Removed)
+	assert.NotNil(t, result.Response)
+
+	result, err = differ.Ingest(context.Background(), []Entity{
+		Timestamped(site, time.Now()),
+		&Device{Name: String("sw1"), Site: site, Serial: String("SN1")},
+		&Interface{Name: String("eth1"), Device: device},
+	})
+	require.NoError(t, err)
+	requireEqualEntityLists(t, EntityList{
+		&Device{Name: String("sw1"), Site: site, Serial: String("SN1")},
+		&Interface{Name: String("eth1"), Device: device},
+	}, result.Sent)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, []Identity{{Type: "interface", Key: `device=(name="sw1",site=(name="dc1")),name="eth0"`}}, result.Removed)
+
+	result, err = differ.Ingest(context.Background(), []Entity{site})
+	require.NoError(t, err)
+	assert.Empty(t, result.Sent)
+	assert.Nil(t, result.Response)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Len(t, result.Removed, 2)
+
+	assert.Len(t, client.Calls(), 2)
+}
+
+func TestDifferResyncInterval(t *testing.T) {
+	client := &MockClient{}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	differ := NewDiffer(client, NewMemorySnapshotStore(), WithDiffResyncInterval(time.Hour))
+	differ.now = func() time.Time { return now }
+
+	entities := []Entity{&Site{Name: String("dc1")}, &Site{Name: String("dc2")}}
+
+	tests := []struct {
+		desc         string
+		after        time.Duration
+		wantSent     int
+		wantFullSync bool
+	}{
+		{desc: "first ingest", wantSent: 2, wantFullSync: true},
+		{desc: "unchanged", after: 30 * time.Minute, wantSent: 0},
+		{desc: "resync due", after: 30 * time.Minute, wantSent: 2, wantFullSync: true},
+		{desc: "unchanged after resync", after: time.Minute, wantSent: 0},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.after)
+		result, err := differ.Ingest(context.Background(), entities)
+		require.NoError(t, err, tt.desc)
+		assert.Len(t, result.Sent, tt.wantSent, tt.desc)
+		assert.Equal(t, tt.wantFullSync, result.FullSync, tt.desc)
+	}
+}
+
+func TestDifferSnapshotNotUpdatedOnFailure(t *testing.T) {
+	tests := []struct {
+		desc       string
+		ingestFunc func([]Entity) (*diodepb.IngestResponse, error)
+		wantErr    string
+	}{
+		{
+			desc: "ingest error",
+			ingestFunc: func([]Entity) (*diodepb.IngestResponse, error) {
+				return nil, errors.New("unavailable")
+			},
+			wantErr: "unavailable",
+		},
+		{
+			desc: "response errors",
+			ingestFunc: func([]Entity) (*diodepb.IngestResponse, error) {
+				return &diodepb.IngestResponse{Errors: []string{"invalid site"}}, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			client := &MockClient{ingestFunc: tt.ingestFunc}
+			store := NewMemorySnapshotStore()
+			differ := NewDiffer(client, store)
+
+			entities := []Entity{&Site{Name: String("dc1")}}
+			_, err := differ.Ingest(context.Background(), entities)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			snapshot, err := store.Load()
+			require.NoError(t, err)
+			assert.Empty(t, snapshot.Hashes)
+
+			client.ingestFunc = nil
+			result, err := differ.Ingest(context.Background(), entities)
+			require.NoError(t, err)
+			assert.Len(t, result.Sent, 1)
+		})
+	}
+}
+
+func TestDifferSendsEntitiesWithoutType(t *testing.T) {
+	client := &MockClient{}
+	differ := NewDiffer(client, NewMemorySnapshotStore())
+
+	entities := []Entity{&protoEntity{entity: &diodepb.Entity{}}}
+	for i := 0; i < 2; i++ {
+		result, err := differ.Ingest(context.Background(), entities)
+		require.NoError(t, err)
+		assert.Len(t, result.Sent, 1)
+	}
+}
+
+func TestDifferMergesDuplicates(t *testing.T) {
+	entities := []Entity{
+		&Site{Name: String("dc1"), Slug: String("dc1"), Description: String("first")},
+		&Site{Name: String("dc1"), Status: String("active"), Description: String("last")},
+	}
+
+	tests := []struct {
+		desc    string
+		policy  MergePolicy
+		want    Entity
+		wantErr string
+	}{
+		{
+			desc:   "keep first",
+			policy: MergeKeepFirst,
+			want:   &Site{Name: String("dc1"), Slug: String("dc1"), Status: String("active"), Description: String("first")},
+		},
+		{
+			desc:   "keep last",
+			policy: MergeKeepLast,
+			want:   &Site{Name: String("dc1"), Slug: String("dc1"), Status: String("active"), Description: String("last")},
+		},
+		{
+			desc:    "fail on conflict",
+			policy:  MergeFailOnConflict,
+			wantErr: `conflicting values of site.description in entities site:name="dc1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			client := &MockClient{}
+			differ := NewDiffer(client, NewMemorySnapshotStore(), WithDiffMergePolicy(tt.policy))
+
+			result, err := differ.Ingest(context.Background(), entities)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				assert.Empty(t, client.Calls())
+				return
+			}
+			require.NoError(t, err)
+			requireEqualEntityLists(t, EntityList{tt.want}, result.Sent)
+
+			result, err = differ.Ingest(context.Background(), entities)
+			require.NoError(t, err)
+			assert.Empty(t, result.Sent)
+			assert.Equal(t, 1, result.Unchanged)
+		})
+	}
+}
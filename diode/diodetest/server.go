@@ -0,0 +1,345 @@
+// Package diodetest provides an in-process fake Diode ingester service for testing code built on the SDK
+package diodetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// apiKeyMetadataName is the metadata key the SDK sends the API key with
+const apiKeyMetadataName = "diode-api-key"
+
+// Response is a scripted reply of the fake ingester service
+type Response struct {
+	// Errors are returned in IngestResponse.Errors
+	Errors []string
+
+	// Err is returned instead of a response, e.g. status.Error(codes.Unavailable, "unavailable")
+	Err error
+
+	// Delay is waited before replying, the wait ends early if the request is canceled
+	Delay time.Duration
+}
+
+// Call is an ingest request received by the fake ingester service
+type Call struct {
+	// Request is the received ingest request
+	Request *diodepb.IngestRequest
+
+	// Metadata is the metadata the request was sent with
+	Metadata metadata.MD
+}
+
+// Server is a fake Diode ingester service listening on a loopback address
+//
+// It records every authenticated ingest request and replies with the scripted responses in order, then with empty
+// successful responses once the script is exhausted. Requests received on an ingest stream are recorded one by one,
+// the stream is replied to with a single scripted response.
+type Server struct {
+	diodepb.UnimplementedIngesterServiceStreamServer
+
+	// Required API key, any non-empty API key is accepted if empty
+	apiKey string
+
+	// Whether the client-streaming ingest RPC replies Unimplemented, as on servers without it
+	withoutStreaming bool
+
+	// gRPC server and its listener
+	server   *grpc.Server
+	listener net.Listener
+
+	// Guards the fields below
+	mu sync.Mutex
+
+	// Scripted responses not yet returned
+	responses []Response
+
+	// Received authenticated requests
+	calls []Call
+}
+
+// Option is a functional option for the Server
+type Option func(*Server)
+
+// WithAPIKey sets the API key requests must be sent with, any non-empty API key is accepted by default
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithResponses sets the scripted responses returned in order
+func WithResponses(responses ...Response) Option {
+	return func(s *Server) {
+		s.responses = append(s.responses, responses...)
+	}
+}
+
+// WithoutStreaming makes the client-streaming ingest RPC reply Unimplemented, as servers without it do
+func WithoutStreaming() Option {
+	return func(s *Server) {
+		s.withoutStreaming = true
+	}
+}
+
+// NewServer starts a fake ingester service on a loopback address
+func NewServer(opts ...Option) (*Server, error) {
+	s := &Server{}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	s.listener = listener
+	s.server = grpc.NewServer()
+	if s.withoutStreaming {
+		diodepb.RegisterIngesterServiceServer(s.server, s)
+	} else {
+		diodepb.RegisterIngesterServiceStreamServer(s.server, s)
+	}
+
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+
+	return s, nil
+}
+
+// Start starts a fake ingester service stopped when the test finishes, failing the test if it cannot be started
+func Start(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s, err := NewServer(opts...)
+	if err != nil {
+		t.Fatalf("failed to start fake Diode server: %v", err)
+	}
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Target returns the target to create a client for the server with, e.g. grpc://127.0.0.1:50051
+func (s *Server) Target() string {
+	return fmt.Sprintf("grpc://%s", s.listener.Addr().String())
+}
+
+// Close stops the server, closing open connections
+func (s *Server) Close() {
+	s.server.Stop()
+}
+
+// Enqueue appends scripted responses, returned in order after the ones already enqueued
+func (s *Server) Enqueue(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, responses...)
+}
+
+// Reset forgets the received requests and the scripted responses not yet returned
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+	s.responses = nil
+}
+
+// Calls returns the received requests along with their metadata
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Requests returns the received requests
+func (s *Server) Requests() []*diodepb.IngestRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reqs := make([]*diodepb.IngestRequest, 0, len(s.calls))
+	for _, c := range s.calls {
+		reqs = append(reqs, c.Request)
+	}
+	return reqs
+}
+
+// Entities returns the entities of all received requests, in the order they were received
+func (s *Server) Entities() []*diodepb.Entity {
+	var entities []*diodepb.Entity
+	for _, req := range s.Requests() {
+		entities = append(entities, req.GetEntities()...)
+	}
+	return entities
+}
+
+// FindEntity returns the first received entity of the given type and name, nil if none was received
+//
+// The type is the name of the entity message, e.g. Device, IPAddress or Role. The name is the value of the name field
+// of the entity, or of its address, prefix or model field for IP addresses, prefixes and device types.
+func (s *Server) FindEntity(entityType string, name string) *diodepb.Entity {
+	for _, entity := range s.Entities() {
+		m := entityMessage(entity)
+		if m == nil || string(m.Descriptor().Name()) != entityType {
+			continue
+		}
+		if entityName(m) == name {
+			return entity
+		}
+	}
+	return nil
+}
+
+// AssertEntityIngested reports a test error unless an entity of the given type and name was received
+func (s *Server) AssertEntityIngested(t testing.TB, entityType string, name string) bool {
+	t.Helper()
+
+	if s.FindEntity(entityType, name) == nil {
+		t.Errorf("no %s named %q was ingested", entityType, name)
+		return false
+	}
+	return true
+}
+
+// AssertRequestCount reports a test error unless the given number of requests was received
+func (s *Server) AssertRequestCount(t testing.TB, n int) bool {
+	t.Helper()
+
+	if got := len(s.Calls()); got != n {
+		t.Errorf("expected %d ingest requests, got %d", n, got)
+		return false
+	}
+	return true
+}
+
+// AssertEntityCount reports a test error unless the given number of entities was received
+func (s *Server) AssertEntityCount(t testing.TB, n int) bool {
+	t.Helper()
+
+	if got := len(s.Entities()); got != n {
+		t.Errorf("expected %d ingested entities, got %d", n, got)
+		return false
+	}
+	return true
+}
+
+// Ingest records the request and replies with the next scripted response
+func (s *Server) Ingest(ctx context.Context, req *diodepb.IngestRequest) (*diodepb.IngestResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if err := s.authenticate(md); err != nil {
+		return nil, err
+	}
+
+	s.record(Call{Request: req, Metadata: md})
+	return s.reply(ctx)
+}
+
+// IngestStream records each request received on the stream and replies with the next scripted response once the
+// client closes it
+func (s *Server) IngestStream(stream diodepb.IngesterService_IngestStreamServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if err := s.authenticate(md); err != nil {
+		return err
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		s.record(Call{Request: req, Metadata: md})
+	}
+
+	resp, err := s.reply(stream.Context())
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// record records a received request
+func (s *Server) record(call Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+// reply returns the next scripted response
+func (s *Server) reply(ctx context.Context) (*diodepb.IngestResponse, error) {
+	s.mu.Lock()
+	var resp Response
+	if len(s.responses) > 0 {
+		resp = s.responses[0]
+		s.responses = s.responses[1:]
+	}
+	s.mu.Unlock()
+
+	if resp.Delay > 0 {
+		timer := time.NewTimer(resp.Delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	if resp.Err != nil {
+		return nil, resp.Err
+	}
+
+	return &diodepb.IngestResponse{Errors: resp.Errors}, nil
+}
+
+// authenticate checks the API key the request was sent with
+func (s *Server) authenticate(md metadata.MD) error {
+	keys := md.Get(apiKeyMetadataName)
+	if len(keys) == 0 || keys[0] == "" {
+		return status.Error(codes.Unauthenticated, "missing API key")
+	}
+	if s.apiKey != "" && keys[0] != s.apiKey {
+		return status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return nil
+}
+
+// entityMessage returns the message set in the entity's oneof, nil if none is set
+func entityMessage(entity *diodepb.Entity) protoreflect.Message {
+	m := entity.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("entity"))
+	if fd == nil {
+		return nil
+	}
+	return m.Get(fd).Message()
+}
+
+// entityName returns the value of the first naming field set on the entity message
+func entityName(m protoreflect.Message) string {
+	for _, name := range []protoreflect.Name{"name", "address", "prefix", "model"} {
+		if fd := m.Descriptor().Fields().ByName(name); fd != nil && m.Has(fd) {
+			return m.Get(fd).String()
+		}
+	}
+	return ""
+}
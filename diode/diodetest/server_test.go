@@ -0,0 +1,210 @@
+package diodetest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netboxlabs/diode-sdk-go/diode"
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+// recordingTB records the errors reported by the assertion helpers instead of failing the test
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestServerRecordsRequests(t *testing.T) {
+	server := diodetest.Start(t, diodetest.WithAPIKey("abcde"))
+
+	client, err := diode.NewClient(server.Target(), "my-producer", "0.1.0", diode.WithAPIKey("abcde"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	entities := []diode.Entity{
+		&diode.Device{Name: diode.String("router-1"), Site: &diode.Site{Name: diode.String("site-1")}},
+		&diode.IPAddress{Address: diode.String("192.168.0.1/24")},
+	}
+	resp, err := client.Ingest(context.Background(), entities, diode.WithIngestStream("lab"))
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetErrors())
+
+	server.AssertRequestCount(t, 1)
+	server.AssertEntityCount(t, 2)
+	server.AssertEntityIngested(t, "Device", "router-1")
+	server.AssertEntityIngested(t, "IPAddress", "192.168.0.1/24")
+
+	calls := server.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "lab", calls[0].Request.GetStream())
+	assert.Equal(t, "my-producer", calls[0].Request.GetProducerAppName())
+	assert.Equal(t, []string{"abcde"}, calls[0].Metadata.Get("diode-api-key"))
+
+	server.Reset()
+	server.AssertRequestCount(t, 0)
+}
+
+func TestServerAPIKey(t *testing.T) {
+	tests := []struct {
+		desc          string
+		serverOpts    []diodetest.Option
+		apiKey        string
+		wantErrCode   codes.Code
+		wantCallCount int
+	}{
+		{
+			desc:          "any API key accepted",
+			apiKey:        "abcde",
+			wantErrCode:   codes.OK,
+			wantCallCount: 1,
+		},
+		{
+			desc:          "matching API key",
+			serverOpts:    []diodetest.Option{diodetest.WithAPIKey("abcde")},
+			apiKey:        "abcde",
+			wantErrCode:   codes.OK,
+			wantCallCount: 1,
+		},
+		{
+			desc:        "wrong API key",
+			serverOpts:  []diodetest.Option{diodetest.WithAPIKey("abcde")},
+			apiKey:      "fghij",
+			wantErrCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t, tt.serverOpts...)
+
+			client, err := diode.NewClient(server.Target(), "my-producer", "0.1.0", diode.WithAPIKey(tt.apiKey))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			_, err = client.Ingest(context.Background(), []diode.Entity{&diode.Site{Name: diode.String("site-1")}})
+			assert.Equal(t, tt.wantErrCode, status.Code(err))
+			server.AssertRequestCount(t, tt.wantCallCount)
+		})
+	}
+}
+
+func TestServerScriptedResponses(t *testing.T) {
+	server := diodetest.Start(t, diodetest.WithResponses(
+		diodetest.Response{Errors: []string{"invalid site"}},
+		diodetest.Response{Err: status.Error(codes.Unavailable, "unavailable")},
+	))
+	server.Enqueue(diodetest.Response{Delay: time.Second})
+
+	client, err := diode.NewClient(server.Target(), "my-producer", "0.1.0", diode.WithAPIKey("abcde"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	entities := []diode.Entity{&diode.Site{Name: diode.String("site-1")}}
+
+	resp, err := client.Ingest(context.Background(), entities)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invalid site"}, resp.GetErrors())
+
+	_, err = client.Ingest(context.Background(), entities)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.Ingest(ctx, entities)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	resp, err = client.Ingest(context.Background(), entities)
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetErrors())
+
+	server.AssertRequestCount(t, 4)
+}
+
+func TestServerAssertions(t *testing.T) {
+	server := diodetest.Start(t)
+
+	client, err := diode.NewClient(server.Target(), "my-producer", "0.1.0", diode.WithAPIKey("abcde"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []diode.Entity{
+		&diode.DeviceType{Model: diode.String("ISR4321")},
+		&diode.Prefix{Prefix: diode.String("10.0.0.0/8")},
+	})
+	require.NoError(t, err)
+
+	assert.NotNil(t, server.FindEntity("DeviceType", "ISR4321"))
+	assert.NotNil(t, server.FindEntity("Prefix", "10.0.0.0/8"))
+	assert.Nil(t, server.FindEntity("Device", "ISR4321"))
+
+	tb := &recordingTB{TB: t}
+	assert.False(t, server.AssertEntityIngested(tb, "Device", "router-1"))
+	assert.False(t, server.AssertRequestCount(tb, 2))
+	assert.False(t, server.AssertEntityCount(tb, 1))
+	assert.Equal(t, []string{
+		`no Device named "router-1" was ingested`,
+		"expected 2 ingest requests, got 1",
+		"expected 1 ingested entities, got 2",
+	}, tb.errors)
+}
+
+func TestServerIngestStream(t *testing.T) {
+	tests := []struct {
+		desc string
+		opts []diodetest.Option
+	}{
+		{
+			desc: "streaming",
+		},
+		{
+			desc: "without streaming",
+			opts: []diodetest.Option{diodetest.WithoutStreaming()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t, append(tt.opts, diodetest.WithResponses(diodetest.Response{Errors: []string{"invalid site"}}))...)
+
+			client, err := diode.NewClient(server.Target(), "my-producer", "0.1.0", diode.WithAPIKey("abcde"))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			entities := []diode.Entity{
+				&diode.Site{Name: diode.String("site-1")},
+				&diode.Site{Name: diode.String("site-2")},
+			}
+			resp, err := client.(diode.StreamingClient).IngestStreaming(context.Background(), entities)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"invalid site"}, resp.GetErrors())
+
+			server.AssertRequestCount(t, 1)
+			server.AssertEntityCount(t, 2)
+			server.AssertEntityIngested(t, "Site", "site-2")
+			assert.Equal(t, []string{"abcde"}, server.Calls()[0].Metadata.Get("diode-api-key"))
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package diode
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// RequestFormat is the format ingest requests are recorded in by a dry-run client
+type RequestFormat int
+
+const (
+	// RequestFormatJSON records each request as an indented protojson document
+	RequestFormatJSON RequestFormat = iota
+
+	// RequestFormatNDJSON records each request as a protojson document on a single line
+	RequestFormatNDJSON
+
+	// RequestFormatBinary records each request as a length-prefixed binary protobuf message
+	RequestFormatBinary
+)
+
+// String returns the name of the request format
+func (f RequestFormat) String() string {
+	switch f {
+	case RequestFormatJSON:
+		return "json"
+	case RequestFormatNDJSON:
+		return "ndjson"
+	case RequestFormatBinary:
+		return "binary"
+	default:
+		return fmt.Sprintf("RequestFormat(%d)", int(f))
+	}
+}
+
+// ext returns the file extension of requests recorded in the format
+func (f RequestFormat) ext() string {
+	switch f {
+	case RequestFormatNDJSON:
+		return ".ndjson"
+	case RequestFormatBinary:
+		return ".binpb"
+	default:
+		return ".json"
+	}
+}
+
+// requestFormatFromExt returns the request format of a file extension
+func requestFormatFromExt(ext string) (RequestFormat, bool) {
+	for _, f := range []RequestFormat{RequestFormatJSON, RequestFormatNDJSON, RequestFormatBinary} {
+		if f.ext() == ext {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// WithDryRun records ingest requests to the writer instead of sending them to the ingester service
+//
+// Requests are built exactly as they would be sent, with the same IDs, stream, producer and SDK details, and Ingest
+// returns an empty response. No connection is made and no API key is required.
+func WithDryRun(w io.Writer, format RequestFormat) ClientOption {
+	return func(c *GRPCClient) {
+		c.recorder = &requestRecorder{w: w, format: format}
+	}
+}
+
+// WithDryRunDir records each ingest request to its own file in the directory instead of sending it to the ingester
+// service, see WithDryRun
+func WithDryRunDir(dir string, format RequestFormat) ClientOption {
+	return func(c *GRPCClient) {
+		c.recorder = &requestRecorder{dir: dir, format: format}
+	}
+}
+
+// requestRecorder writes ingest requests to a writer or directory
+type requestRecorder struct {
+	// Writer the requests are written to, nil when writing to a directory
+	w io.Writer
+
+	// Directory the requests are written to, one file per request
+	dir string
+
+	// Format of the recorded requests
+	format RequestFormat
+
+	// Clock used for naming request files
+	now func() time.Time
+
+	// Serializes writes
+	mu sync.Mutex
+}
+
+// open prepares the recorder, creating its directory if needed
+func (r *requestRecorder) open() error {
+	if r.now == nil {
+		r.now = time.Now
+	}
+
+	if r.w != nil {
+		return nil
+	}
+
+	if r.dir == "" {
+		return errors.New("dry-run writer or directory is required")
+	}
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create dry-run directory: %w", err)
+	}
+
+	return nil
+}
+
+// write records the request
+func (r *requestRecorder) write(req *diodepb.IngestRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.w != nil {
+		return writeRequest(r.w, req, r.format)
+	}
+
+	name := fmt.Sprintf("%020d-%s%s", r.now().UnixNano(), req.GetId(), r.format.ext())
+
+	f, err := os.Create(filepath.Join(r.dir, name))
+	if err != nil {
+		return err
+	}
+
+	err = writeRequest(f, req, r.format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeRequest writes the request to w in the given format
+func writeRequest(w io.Writer, req *diodepb.IngestRequest, format RequestFormat) error {
+	switch format {
+	case RequestFormatBinary:
+		_, err := protodelim.MarshalTo(w, req)
+		return err
+	case RequestFormatNDJSON, RequestFormatJSON:
+		opts := protojson.MarshalOptions{}
+		if format == RequestFormatJSON {
+			opts.Multiline = true
+			opts.Indent = "  "
+		}
+		b, err := opts.Marshal(req)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(b, '\n'))
+		return err
+	default:
+		return fmt.Errorf("unsupported request format %s", format)
+	}
+}
+
+// RequestReader reads ingest requests recorded by a dry-run client
+type RequestReader struct {
+	// Buffered source of the requests
+	r *bufio.Reader
+
+	// Decoder of JSON and NDJSON requests, nil for binary requests
+	dec *json.Decoder
+}
+
+// NewRequestReader creates a reader of the requests recorded to r in the given format
+func NewRequestReader(r io.Reader, format RequestFormat) *RequestReader {
+	rr := &RequestReader{r: bufio.NewReader(r)}
+	if format != RequestFormatBinary {
+		rr.dec = json.NewDecoder(rr.r)
+	}
+	return rr
+}
+
+// Next returns the next recorded request, io.EOF once all requests have been read
+func (rr *RequestReader) Next() (*diodepb.IngestRequest, error) {
+	req := &diodepb.IngestRequest{}
+
+	if rr.dec == nil {
+		if err := protodelim.UnmarshalFrom(rr.r, req); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	var raw json.RawMessage
+	if err := rr.dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(raw, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ReadRequestDir reads the requests recorded to a directory by a dry-run client, in the order they were recorded
+func ReadRequestDir(dir string) ([]*diodepb.IngestRequest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if _, ok := requestFormatFromExt(filepath.Ext(entry.Name())); ok && !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var reqs []*diodepb.IngestRequest
+	for _, name := range names {
+		format, _ := requestFormatFromExt(filepath.Ext(name))
+		fileReqs, err := readRequestFile(filepath.Join(dir, name), format)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, fileReqs...)
+	}
+
+	return reqs, nil
+}
+
+// readRequestFile reads all requests recorded to a file
+func readRequestFile(path string, format RequestFormat) ([]*diodepb.IngestRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var reqs []*diodepb.IngestRequest
+	rr := NewRequestReader(f, format)
+	for {
+		req, err := rr.Next()
+		if errors.Is(err, io.EOF) {
+			return reqs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		reqs = append(reqs, req)
+	}
+}
+
+// ReplayRequests sends recorded requests through the client, keeping their request IDs, streams and entities
+//
+// The requests are sent in order, stopping at the first failure. The producer and SDK details are the ones of the
+// replaying client.
+func ReplayRequests(ctx context.Context, client Client, reqs []*diodepb.IngestRequest) ([]*diodepb.IngestResponse, error) {
+	resps := make([]*diodepb.IngestResponse, 0, len(reqs))
+	for _, req := range reqs {
+		entities := make([]Entity, 0, len(req.GetEntities()))
+		for _, entity := range req.GetEntities() {
+			// the client normalizes and stamps the entities in place, the recorded requests are left unchanged
+			entities = append(entities, &protoEntity{entity: proto.Clone(entity).(*diodepb.Entity)})
+		}
+
+		resp, err := client.Ingest(ctx, entities, WithRequestID(req.GetId()), WithIngestStream(req.GetStream()))
+		if err != nil {
+			return resps, fmt.Errorf("failed to replay request %s: %w", req.GetId(), err)
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
@@ -0,0 +1,186 @@
+package diode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestDryRunWriter(t *testing.T) {
+	tests := []struct {
+		desc   string
+		format RequestFormat
+	}{
+		{
+			desc:   "json",
+			format: RequestFormatJSON,
+		},
+		{
+			desc:   "ndjson",
+			format: RequestFormatNDJSON,
+		},
+		{
+			desc:   "binary",
+			format: RequestFormatBinary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			// no API key and an unreachable target, nothing is sent
+			client, err := NewClient("grpc://127.0.0.1:1", "my-producer", "0.1.0", WithDryRun(&buf, tt.format), WithStream("lab"))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			requestIDs := []string{"6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11", "9a1c6bb2-3a0f-4a53-9a47-5b0a3a7c1e22"}
+			for _, id := range requestIDs {
+				resp, err := client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}}, WithRequestID(id))
+				require.NoError(t, err)
+				assert.Empty(t, resp.GetErrors())
+			}
+
+			if tt.format == RequestFormatNDJSON {
+				assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+			}
+
+			rr := NewRequestReader(&buf, tt.format)
+			for _, id := range requestIDs {
+				req, err := rr.Next()
+				require.NoError(t, err)
+				assert.Equal(t, id, req.GetId())
+				assert.Equal(t, "lab", req.GetStream())
+				assert.Equal(t, "my-producer", req.GetProducerAppName())
+				assert.Equal(t, "0.1.0", req.GetProducerAppVersion())
+				assert.Equal(t, SDKName, req.GetSdkName())
+				assert.Equal(t, SDKVersion, req.GetSdkVersion())
+				require.Len(t, req.GetEntities(), 1)
+				assert.Equal(t, "site-1", req.GetEntities()[0].GetSite().GetName())
+				assert.NotNil(t, req.GetEntities()[0].GetTimestamp())
+			}
+
+			_, err = rr.Next()
+			assert.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestDryRunValidation(t *testing.T) {
+	var buf bytes.Buffer
+
+	client, err := NewClient("grpc://127.0.0.1:1", "my-producer", "0.1.0", WithDryRun(&buf, RequestFormatNDJSON), WithValidation(ValidationSkipInvalid))
+	require.NoError(t, err)
+
+	resp, err := client.Ingest(context.Background(), []Entity{validSite("site-1"), &Site{Name: String("site-2")}})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.GetErrors())
+	assert.True(t, strings.HasPrefix(resp.GetErrors()[0], "entity 1: "))
+
+	req, err := NewRequestReader(&buf, RequestFormatNDJSON).Next()
+	require.NoError(t, err)
+	require.Len(t, req.GetEntities(), 1)
+	assert.Equal(t, "site-1", req.GetEntities()[0].GetSite().GetName())
+}
+
+func TestDryRunDirReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	dryRun, err := NewClient("grpc://127.0.0.1:1", "my-producer", "0.1.0", WithDryRunDir(dir, RequestFormatBinary))
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = dryRun.Ingest(context.Background(), []Entity{Timestamped(&Device{Name: String("router-1")}, ts)}, WithIngestStream("lab"))
+	require.NoError(t, err)
+	_, err = dryRun.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}, &Role{Name: String("core")}})
+	require.NoError(t, err)
+	require.NoError(t, dryRun.Close())
+
+	recorded, err := ReadRequestDir(dir)
+	require.NoError(t, err)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, "router-1", recorded[0].GetEntities()[0].GetDevice().GetName())
+	assert.Len(t, recorded[1].GetEntities(), 2)
+
+	server := diodetest.Start(t)
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	resps, err := ReplayRequests(context.Background(), client, recorded)
+	require.NoError(t, err)
+	assert.Len(t, resps, 2)
+
+	sent := server.Requests()
+	require.Len(t, sent, 2)
+	for i, req := range sent {
+		assert.Equal(t, recorded[i].GetId(), req.GetId())
+		assert.Equal(t, recorded[i].GetStream(), req.GetStream())
+		require.Len(t, req.GetEntities(), len(recorded[i].GetEntities()))
+		for j, entity := range req.GetEntities() {
+			assert.True(t, proto.Equal(recorded[i].GetEntities()[j], entity))
+		}
+	}
+	assert.Equal(t, ts, sent[0].GetEntities()[0].GetTimestamp().AsTime())
+}
+
+func TestReplayRequestsStopsAtFirstFailure(t *testing.T) {
+	client := &MockClient{ingestFunc: func(_ []Entity) (*diodepb.IngestResponse, error) {
+		return nil, errors.New("unavailable")
+	}}
+
+	reqs := []*diodepb.IngestRequest{
+		{Id: "6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11", Stream: "latest", Entities: []*diodepb.Entity{(&Site{Name: String("site-1")}).ConvertToProtoEntity()}},
+		{Id: "9a1c6bb2-3a0f-4a53-9a47-5b0a3a7c1e22", Stream: "latest", Entities: []*diodepb.Entity{(&Site{Name: String("site-2")}).ConvertToProtoEntity()}},
+	}
+
+	resps, err := ReplayRequests(context.Background(), client, reqs)
+	require.EqualError(t, err, "failed to replay request 6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11: unavailable")
+	assert.Empty(t, resps)
+	assert.Len(t, client.Calls(), 1)
+}
+
+func TestReplayRequestsDoesNotModifyRequests(t *testing.T) {
+	server := diodetest.Start(t)
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithNormalizers(DefaultNormalizers()...))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	reqs := []*diodepb.IngestRequest{
+		{Id: "6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11", Stream: "latest", Entities: []*diodepb.Entity{(&Prefix{Prefix: String("10.0.0.1/24")}).ConvertToProtoEntity()}},
+	}
+	want := proto.Clone(reqs[0])
+
+	_, err = ReplayRequests(context.Background(), client, reqs)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(want, reqs[0]), "recorded request modified: %v", reqs[0])
+
+	sent := server.Requests()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "10.0.0.0/24", sent[0].GetEntities()[0].GetPrefix().GetPrefix())
+	assert.NotNil(t, sent[0].GetEntities()[0].GetTimestamp())
+}
+
+func TestNewClientDryRunDirRequired(t *testing.T) {
+	_, err := NewClient("grpc://127.0.0.1:1", "my-producer", "0.1.0", WithDryRunDir("", RequestFormatJSON))
+	require.EqualError(t, err, "dry-run writer or directory is required")
+}
@@ -0,0 +1,271 @@
+package diode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// EntityFromProto converts a diodepb.Entity back to the entity set in its oneof, e.g. a *Device
+//
+// The entity is wrapped in a TimestampedEntity if the diodepb.Entity has a discovery timestamp.
+func EntityFromProto(m *diodepb.Entity) (Entity, error) {
+	if m == nil {
+		return nil, errors.New("entity is nil")
+	}
+
+	entity := entityFromProto(m)
+	if entity == nil {
+		return nil, errors.New("entity has no type set")
+	}
+
+	return timestampedFromProto(m, entity), nil
+}
+
+// fromProtoSlice converts a slice of proto messages with the given constructor, returning nil for an empty slice
+func fromProtoSlice[M any, E any](ms []M, fromProto func(M) E) []E {
+	if len(ms) == 0 {
+		return nil
+	}
+	es := make([]E, 0, len(ms))
+	for _, m := range ms {
+		es = append(es, fromProto(m))
+	}
+	return es
+}
+
+// protoEntity is an Entity wrapping an already converted diodepb.Entity, e.g. one read back from a recorded request
+type protoEntity struct {
+	entity *diodepb.Entity
+}
+
+// ConvertToProtoMessage returns the message set in the wrapped entity
+func (e *protoEntity) ConvertToProtoMessage() proto.Message {
+	m := e.entity.ProtoReflect()
+	if fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("entity")); fd != nil {
+		return m.Get(fd).Message().Interface()
+	}
+	return e.entity
+}
+
+// ConvertToProtoEntity returns the wrapped entity
+func (e *protoEntity) ConvertToProtoEntity() *diodepb.Entity {
+	return e.entity
+}
+
+// NewEntity returns a new empty entity of the given type, which is the name of the matching diodepb.Entity field, e.g.
+// device or ip_address
+func NewEntity(entityType string) (Entity, error) {
+	newEntity, ok := entityTypes[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity type %q", entityType)
+	}
+	return newEntity(), nil
+}
+
+// EntityList is a list of entities of any type, e.g. loaded from a configuration file or an API payload
+//
+// Each entity is (un)marshalled as a JSON or YAML object wrapping it under its type, which is the name of the matching
+// diodepb.Entity field, e.g. {"device": {...}} or {"ip_address": {...}}. The object of a TimestampedEntity also holds
+// its discovery timestamp, e.g. {"site": {...}, "timestamp": "2024-01-02T03:04:05Z"}.
+type EntityList []Entity
+
+// MarshalJSON marshals the entities as JSON objects wrapping each entity under its type
+func (l EntityList) MarshalJSON() ([]byte, error) {
+	items, err := l.wrap()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(items)
+}
+
+// UnmarshalJSON unmarshals JSON objects wrapping each entity under its type, rejecting unknown fields
+func (l *EntityList) UnmarshalJSON(data []byte) error {
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	if items == nil {
+		*l = nil
+		return nil
+	}
+
+	list := make(EntityList, 0, len(items))
+	for i, item := range items {
+		keys := make([]string, 0, len(item))
+		for k := range item {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		key, err := entityWrapperKey(keys)
+		if err != nil {
+			return fmt.Errorf("entity %d: %w", i, err)
+		}
+
+		entity := entityTypes[key]()
+		dec := json.NewDecoder(bytes.NewReader(item[key]))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(entity); err != nil {
+			return fmt.Errorf("entity %d: invalid %s: %w", i, key, err)
+		}
+
+		if raw, ok := item[entityTimestampKey]; ok {
+			var ts time.Time
+			if err := json.Unmarshal(raw, &ts); err != nil {
+				return fmt.Errorf("entity %d: invalid %s: %w", i, entityTimestampKey, err)
+			}
+			entity = Timestamped(entity, ts)
+		}
+
+		list = append(list, entity)
+	}
+
+	*l = list
+	return nil
+}
+
+// MarshalYAML marshals the entities as YAML mappings wrapping each entity under its type
+func (l EntityList) MarshalYAML() (any, error) {
+	return l.wrap()
+}
+
+// UnmarshalYAML unmarshals YAML mappings wrapping each entity under its type
+func (l *EntityList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: expected a sequence of entities", value.Line)
+	}
+
+	list := make(EntityList, 0, len(value.Content))
+	for i, item := range value.Content {
+		if item.Kind != yaml.MappingNode {
+			return fmt.Errorf("entity %d: line %d: expected a mapping", i, item.Line)
+		}
+
+		values := make(map[string]*yaml.Node, len(item.Content)/2)
+		keys := make([]string, 0, len(item.Content)/2)
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			values[item.Content[j].Value] = item.Content[j+1]
+			keys = append(keys, item.Content[j].Value)
+		}
+
+		key, err := entityWrapperKey(keys)
+		if err != nil {
+			return fmt.Errorf("entity %d: line %d: %w", i, item.Line, err)
+		}
+
+		entity := entityTypes[key]()
+		if err := decodeYAMLKnownFields(values[key], entity); err != nil {
+			return fmt.Errorf("entity %d: invalid %s: %w", i, key, err)
+		}
+
+		if node, ok := values[entityTimestampKey]; ok {
+			var ts time.Time
+			if err := node.Decode(&ts); err != nil {
+				return fmt.Errorf("entity %d: invalid %s: %w", i, entityTimestampKey, err)
+			}
+			entity = Timestamped(entity, ts)
+		}
+
+		list = append(list, entity)
+	}
+
+	*l = list
+	return nil
+}
+
+// decodeYAMLKnownFields decodes a YAML node into the value, rejecting fields the value does not have like the JSON
+// decoding of entities, the node is re-encoded at its line so that errors report the lines of the document
+func decodeYAMLKnownFields(node *yaml.Node, v any) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	if node.Line > 1 {
+		data = append(bytes.Repeat([]byte("\n"), node.Line-1), data...)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
+
+// wrap returns the objects wrapping each entity under its type
+func (l EntityList) wrap() ([]map[string]any, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	items := make([]map[string]any, 0, len(l))
+	for i, e := range l {
+		var ts time.Time
+		for {
+			te, ok := e.(*TimestampedEntity)
+			if !ok {
+				break
+			}
+			if ts.IsZero() {
+				ts = te.Timestamp
+			}
+			e = te.Entity
+		}
+
+		if e == nil || reflect.ValueOf(e).IsNil() {
+			return nil, fmt.Errorf("entity %d: entity is nil", i)
+		}
+
+		key := entityTypeName(e.ConvertToProtoEntity())
+		newEntity, ok := entityTypes[key]
+		if !ok || reflect.TypeOf(newEntity()) != reflect.TypeOf(e) {
+			return nil, fmt.Errorf("entity %d: unsupported entity type %T", i, e)
+		}
+
+		item := map[string]any{key: e}
+		if !ts.IsZero() {
+			item[entityTimestampKey] = ts
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// entityTypeName returns the name of the entity's oneof field, "unknown" if none is set
+func entityTypeName(entity *diodepb.Entity) string {
+	m := entity.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("entity"))
+	if fd == nil {
+		return "unknown"
+	}
+	return string(fd.Name())
+}
+
+// entityWrapperKey returns the entity type among the keys of an object wrapping an entity
+func entityWrapperKey(keys []string) (string, error) {
+	var key string
+	for _, k := range keys {
+		if k == entityTimestampKey {
+			continue
+		}
+		if _, ok := entityTypes[k]; !ok {
+			return "", fmt.Errorf("unknown entity type %q", k)
+		}
+		if key != "" {
+			return "", fmt.Errorf("multiple entity types %q and %q", key, k)
+		}
+		key = k
+	}
+
+	if key == "" {
+		return "", errors.New("missing entity type")
+	}
+	return key, nil
+}
@@ -0,0 +1,281 @@
+package diode
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestTimestampedEntity(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc          string
+		entity        *TimestampedEntity
+		wantTimestamp *timestamppb.Timestamp
+	}{
+		{
+			desc:          "timestamp set",
+			entity:        Timestamped(&Site{Name: String("site-1")}, ts),
+			wantTimestamp: timestamppb.New(ts),
+		},
+		{
+			desc:          "zero timestamp left unset",
+			entity:        Timestamped(&Site{Name: String("site-1")}, time.Time{}),
+			wantTimestamp: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			entity := tt.entity.ConvertToProtoEntity()
+			require.Equal(t, "site-1", entity.GetSite().GetName())
+			require.Equal(t, tt.wantTimestamp, entity.GetTimestamp())
+		})
+	}
+}
+
+func TestEntityTypeName(t *testing.T) {
+	tests := []struct {
+		desc   string
+		entity *diodepb.Entity
+		want   string
+	}{
+		{
+			desc:   "site",
+			entity: (&Site{}).ConvertToProtoEntity(),
+			want:   "site",
+		},
+		{
+			desc:   "device role",
+			entity: (&Role{}).ConvertToProtoEntity(),
+			want:   "device_role",
+		},
+		{
+			desc:   "no entity set",
+			entity: &diodepb.Entity{},
+			want:   "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, entityTypeName(tt.entity))
+		})
+	}
+}
+
+func testEntityList() EntityList {
+	site := &Site{Name: String("site-1"), Slug: String("site-1"), Status: String("active"), Tags: []*Tag{{Name: String("lab")}}}
+	device := &Device{
+		Name:       String("router-1"),
+		DeviceType: &DeviceType{Model: String("ISR4321"), Manufacturer: &Manufacturer{Name: String("Cisco")}},
+		Role:       &Role{Name: String("core")},
+		Site:       site,
+	}
+	iface := &Interface{Name: String("GigabitEthernet0/0"), Device: device, Enabled: Bool(false), Mtu: Int32(1500)}
+
+	return EntityList{
+		site,
+		Timestamped(device, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		&Platform{Name: String("ios")},
+		&Manufacturer{Name: String("Cisco")},
+		&Role{Name: String("core"), Color: String("ff0000")},
+		&DeviceType{Model: String("ISR4321")},
+		iface,
+		&IPAddress{Address: String("192.168.0.1/24"), AssignedObject: iface},
+		&Prefix{Prefix: String("192.168.0.0/24"), Site: site},
+		&ClusterGroup{Name: String("group-1")},
+		&ClusterType{Name: String("type-1")},
+		&Cluster{Name: String("cluster-1")},
+		&VirtualMachine{Name: String("vm-1"), Vcpus: Int32(2)},
+		&VMInterface{Name: String("eth0")},
+		&VirtualDisk{Name: String("disk-1"), Size: Int32(20)},
+	}
+}
+
+func requireEqualEntityLists(t *testing.T, want EntityList, got EntityList) {
+	t.Helper()
+
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.IsType(t, want[i], got[i])
+		assert.True(t, proto.Equal(want[i].ConvertToProtoEntity(), got[i].ConvertToProtoEntity()), "entity %d", i)
+	}
+}
+
+func TestEntityListJSON(t *testing.T) {
+	want := testEntityList()
+
+	b, err := json.Marshal(want)
+	require.NoError(t, err)
+
+	var got EntityList
+	require.NoError(t, json.Unmarshal(b, &got))
+	requireEqualEntityLists(t, want, got)
+
+	b2, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(b), string(b2))
+}
+
+func TestEntityListYAML(t *testing.T) {
+	want := testEntityList()
+
+	b, err := yaml.Marshal(want)
+	require.NoError(t, err)
+
+	var got EntityList
+	require.NoError(t, yaml.Unmarshal(b, &got))
+	requireEqualEntityLists(t, want, got)
+}
+
+func TestEntityListUnmarshal(t *testing.T) {
+	data := `[
+		{"device": {"name": "router-1", "site": {"name": "site-1"}, "device_type": {"model": "ISR4321"}}},
+		{"ip_address": {"address": "10.0.0.1/8", "assigned_object": {"name": "eth0"}}, "timestamp": "2024-01-02T03:04:05Z"},
+		{"device_role": {"name": "core"}}
+	]`
+
+	var fromJSON EntityList
+	require.NoError(t, json.Unmarshal([]byte(data), &fromJSON))
+
+	var fromYAML EntityList
+	require.NoError(t, yaml.Unmarshal([]byte(data), &fromYAML))
+
+	for _, list := range []EntityList{fromJSON, fromYAML} {
+		require.Len(t, list, 3)
+
+		device := list[0].(*Device)
+		assert.Equal(t, "router-1", device.GetName())
+		assert.Equal(t, "site-1", device.GetSite().GetName())
+		assert.Equal(t, "ISR4321", device.GetDeviceType().GetModel())
+
+		ip := list[1].(*TimestampedEntity)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ip.Timestamp.UTC())
+		assert.Equal(t, "eth0", ip.Entity.(*IPAddress).AssignedObject.GetName())
+
+		assert.Equal(t, "core", list[2].(*Role).GetName())
+	}
+}
+
+func TestEntityListUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		desc    string
+		data    string
+		wantErr string
+	}{
+		{
+			desc:    "unknown entity type",
+			data:    `[{"tenant": {"name": "tenant-1"}}]`,
+			wantErr: `entity 0: unknown entity type "tenant"`,
+		},
+		{
+			desc:    "multiple entity types",
+			data:    `[{"site": {"name": "site-1"}, "device": {"name": "router-1"}}]`,
+			wantErr: `entity 0: multiple entity types "device" and "site"`,
+		},
+		{
+			desc:    "missing entity type",
+			data:    `[{"timestamp": "2024-01-02T03:04:05Z"}]`,
+			wantErr: "entity 0: missing entity type",
+		},
+		{
+			desc:    "unknown field",
+			data:    `[{"site": {"nam": "site-1"}}]`,
+			wantErr: `entity 0: invalid site: json: unknown field "nam"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var list EntityList
+			require.EqualError(t, json.Unmarshal([]byte(tt.data), &list), tt.wantErr)
+		})
+	}
+}
+
+func TestEntityListUnmarshalYAMLUnknownField(t *testing.T) {
+	var list EntityList
+	err := yaml.Unmarshal([]byte("- site:\n    name: site-1\n    nam: site-1\n"), &list)
+	require.EqualError(t, err, "entity 0: invalid site: yaml: unmarshal errors:\n  line 3: field nam not found in type diode.Site")
+}
+
+func TestEntityListMarshalUnsupportedEntity(t *testing.T) {
+	_, err := json.Marshal(EntityList{&protoEntity{entity: (&Site{}).ConvertToProtoEntity()}})
+	require.ErrorContains(t, err, "entity 0: unsupported entity type *diode.protoEntity")
+}
+
+func TestNewEntity(t *testing.T) {
+	tests := []struct {
+		desc       string
+		entityType string
+		want       Entity
+		wantErr    string
+	}{
+		{
+			desc:       "device",
+			entityType: "device",
+			want:       &Device{},
+		},
+		{
+			desc:       "device role",
+			entityType: "device_role",
+			want:       &Role{},
+		},
+		{
+			desc:       "unknown entity type",
+			entityType: "tenant",
+			wantErr:    `unknown entity type "tenant"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			entity, err := NewEntity(tt.entityType)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, entity)
+		})
+	}
+}
+
+func TestEntityOneofCoverage(t *testing.T) {
+	oneof := (*diodepb.Entity)(nil).ProtoReflect().Descriptor().Oneofs().ByName("entity")
+	require.NotNil(t, oneof)
+
+	fields := oneof.Fields()
+	require.Len(t, entityTypes, fields.Len(), "entityTypes does not match the diodepb.Entity oneof, run make codegen")
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := string(fd.Name())
+
+		t.Run(name, func(t *testing.T) {
+			newEntity, ok := entityTypes[name]
+			require.True(t, ok, "diodepb.Entity member %s has no entity, run make codegen", name)
+
+			entity := newEntity()
+			assert.Equal(t, fd.Message().FullName(), entity.ConvertToProtoMessage().ProtoReflect().Descriptor().FullName())
+
+			m := entity.ConvertToProtoEntity()
+			assert.Equal(t, fd, m.ProtoReflect().WhichOneof(oneof))
+			assert.Equal(t, name, entityTypeName(m))
+
+			got, err := EntityFromProto(m)
+			require.NoError(t, err)
+			assert.IsType(t, entity, got)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package diode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// identityFields are the fields identifying the NetBox objects of the messages, by message name
+//
+// Message fields are identified by their own identity fields, e.g. a device by its name and the name of its site.
+var identityFields = map[protoreflect.Name][]protoreflect.Name{
+	"Site":           {"name"},
+	"Platform":       {"name"},
+	"Manufacturer":   {"name"},
+	"Device":         {"name", "site"},
+	"Role":           {"name"},
+	"DeviceType":     {"manufacturer", "model"},
+	"Interface":      {"device", "name"},
+	"IPAddress":      {"address"},
+	"Prefix":         {"prefix"},
+	"ClusterGroup":   {"name"},
+	"ClusterType":    {"name"},
+	"Cluster":        {"name"},
+	"VirtualMachine": {"name", "cluster"},
+	"VMInterface":    {"virtual_machine", "name"},
+	"VirtualDisk":    {"virtual_machine", "name"},
+	"Tag":            {"name"},
+}
+
+// Identity identifies the NetBox object of an entity, entities with the same identity describe the same object
+type Identity struct {
+	// Type is the entity type, which is the name of the matching diodepb.Entity field, e.g. device
+	Type string
+
+	// Key is the natural key of the object built from its identifying fields, e.g. name="sw1",site=(name="dc1")
+	Key string
+}
+
+// String returns the identity as type:key, e.g. device:name="sw1",site=(name="dc1")
+func (i Identity) String() string {
+	return i.Type + ":" + i.Key
+}
+
+// IdentityOf returns the identity of an entity
+//
+// Entities are identified by the fields NetBox matches objects by: devices by name and site, device types by
+// manufacturer and model, interfaces by device and name, IP addresses by address, prefixes by prefix, virtual machines
+// by name and cluster, virtual machine interfaces and disks by virtual machine and name, and other entities by name.
+func IdentityOf(entity Entity) (Identity, error) {
+	id, ok := entityIdentity(entity.ConvertToProtoEntity())
+	if !ok {
+		return Identity{}, fmt.Errorf("entity %T has no type set", entity)
+	}
+	return id, nil
+}
+
+// entityIdentity returns the identity of the message set in a diodepb.Entity, false if none is set
+func entityIdentity(entity *diodepb.Entity) (Identity, bool) {
+	m := entity.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("entity"))
+	if fd == nil {
+		return Identity{}, false
+	}
+	return Identity{Type: string(fd.Name()), Key: identityKey(m.Get(fd).Message())}, true
+}
+
+// hasIdentity reports whether the NetBox objects of a message are identified by some of its fields
+func hasIdentity(m protoreflect.Message) bool {
+	_, ok := identityFields[m.Descriptor().Name()]
+	return ok
+}
+
+// identityKey returns the natural key of a message built from its identifying fields, unset fields are left empty
+func identityKey(m protoreflect.Message) string {
+	var b strings.Builder
+	fields := m.Descriptor().Fields()
+	for i, name := range identityFields[m.Descriptor().Name()] {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(string(name))
+		b.WriteByte('=')
+
+		fd := fields.ByName(name)
+		if fd == nil || !m.Has(fd) {
+			continue
+		}
+		if fd.Message() != nil {
+			b.WriteString("(" + identityKey(m.Get(fd).Message()) + ")")
+			continue
+		}
+		b.WriteString(strconv.Quote(m.Get(fd).String()))
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package diode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestIdentityOf(t *testing.T) {
+	tests := []struct {
+		desc    string
+		entity  Entity
+		want    string
+		wantErr string
+	}{
+		{
+			desc:   "site",
+			entity: &Site{Name: String("dc1"), Status: String("active")},
+			want:   `site:name="dc1"`,
+		},
+		{
+			desc:   "device by name and site",
+			entity: &Device{Name: String("sw1"), Site: &Site{Name: String("dc1"), Slug: String("dc1")}, Serial: String("SN1")},
+			want:   `device:name="sw1",site=(name="dc1")`,
+		},
+		{
+			desc:   "device without site",
+			entity: &Device{Name: String("sw1")},
+			want:   `device:name="sw1",site=`,
+		},
+		{
+			desc:   "device type by manufacturer and model",
+			entity: &DeviceType{Model: String("7050"), Manufacturer: &Manufacturer{Name: String("Arista")}},
+			want:   `device_type:manufacturer=(name="Arista"),model="7050"`,
+		},
+		{
+			desc:   "interface by device and name",
+			entity: &Interface{Name: String("eth0"), Device: &Device{Name: String("sw1"), Site: &Site{Name: String("dc1")}}},
+			want:   `interface:device=(name="sw1",site=(name="dc1")),name="eth0"`,
+		},
+		{
+			desc:   "ip address by address",
+			entity: &IPAddress{Address: String("10.0.0.1/24"), AssignedObject: &Interface{Name: String("eth0")}},
+			want:   `ip_address:address="10.0.0.1/24"`,
+		},
+		{
+			desc:   "quoted values",
+			entity: &Role{Name: String(`core "a",b`)},
+			want:   `device_role:name="core \"a\",b"`,
+		},
+		{
+			desc:   "timestamped entity",
+			entity: Timestamped(&Prefix{Prefix: String("10.0.0.0/24")}, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+			want:   `prefix:prefix="10.0.0.0/24"`,
+		},
+		{
+			desc:    "no type",
+			entity:  &protoEntity{entity: &diodepb.Entity{}},
+			wantErr: "entity *diode.protoEntity has no type set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			id, err := IdentityOf(tt.entity)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id.String())
+		})
+	}
+}
+
+func TestIdentityFieldsCoverMessages(t *testing.T) {
+	entity := (*diodepb.Entity)(nil).ProtoReflect().Descriptor()
+	messages := []protoreflect.MessageDescriptor{(*diodepb.Tag)(nil).ProtoReflect().Descriptor()}
+	fields := entity.Oneofs().ByName("entity").Fields()
+	for i := 0; i < fields.Len(); i++ {
+		messages = append(messages, fields.Get(i).Message())
+	}
+
+	for _, md := range messages {
+		t.Run(string(md.Name()), func(t *testing.T) {
+			names, ok := identityFields[md.Name()]
+			require.True(t, ok, "%s has no identity fields", md.Name())
+			for _, name := range names {
+				assert.NotNil(t, md.Fields().ByName(name), "%s has no field %s", md.Name(), name)
+			}
+		})
+	}
+}
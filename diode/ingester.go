@@ -3,7 +3,10 @@
 package diode
 
 import (
+	"time"
+
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
 )
@@ -16,16 +19,16 @@ type Entity interface {
 
 // Cluster is based on diodepb.Cluster
 type Cluster struct {
-	Name        *string
-	Type        *ClusterType
-	Group       *ClusterGroup
-	Site        *Site
-	Status      *string
-	Description *string
-	Tags        []*Tag
+	Name        *string       `json:"name,omitempty" yaml:"name,omitempty"`
+	Type        *ClusterType  `json:"type,omitempty" yaml:"type,omitempty"`
+	Group       *ClusterGroup `json:"group,omitempty" yaml:"group,omitempty"`
+	Site        *Site         `json:"site,omitempty" yaml:"site,omitempty"`
+	Status      *string       `json:"status,omitempty" yaml:"status,omitempty"`
+	Description *string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []*Tag        `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageCluster converts a Cluster to a diodepb.Cluster
+// ConvertToProtoMessage converts a Cluster to a diodepb.Cluster
 func (e *Cluster) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Cluster{
 		Name:        e.GetName(),
@@ -95,7 +98,23 @@ func (e *Cluster) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityCluster converts a Cluster to a diodepb.Entity
+// ClusterFromProto converts a diodepb.Cluster to a Cluster, returning nil for nil
+func ClusterFromProto(m *diodepb.Cluster) *Cluster {
+	if m == nil {
+		return nil
+	}
+	return &Cluster{
+		Name:        nonZeroPtr(m.GetName()),
+		Type:        ClusterTypeFromProto(m.GetType()),
+		Group:       ClusterGroupFromProto(m.GetGroup()),
+		Site:        SiteFromProto(m.GetSite()),
+		Status:      nonZeroPtr(m.GetStatus()),
+		Description: clonePtr(m.Description),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Cluster to a diodepb.Entity
 func (e *Cluster) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Cluster{
@@ -106,13 +125,13 @@ func (e *Cluster) ConvertToProtoEntity() *diodepb.Entity {
 
 // ClusterGroup is based on diodepb.ClusterGroup
 type ClusterGroup struct {
-	Name        *string
-	Slug        *string
-	Description *string
-	Tags        []*Tag
+	Name        *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageClusterGroup converts a ClusterGroup to a diodepb.ClusterGroup
+// ConvertToProtoMessage converts a ClusterGroup to a diodepb.ClusterGroup
 func (e *ClusterGroup) ConvertToProtoMessage() proto.Message {
 	return &diodepb.ClusterGroup{
 		Name:        e.GetName(),
@@ -155,7 +174,20 @@ func (e *ClusterGroup) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityClusterGroup converts a ClusterGroup to a diodepb.Entity
+// ClusterGroupFromProto converts a diodepb.ClusterGroup to a ClusterGroup, returning nil for nil
+func ClusterGroupFromProto(m *diodepb.ClusterGroup) *ClusterGroup {
+	if m == nil {
+		return nil
+	}
+	return &ClusterGroup{
+		Name:        nonZeroPtr(m.GetName()),
+		Slug:        nonZeroPtr(m.GetSlug()),
+		Description: clonePtr(m.Description),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a ClusterGroup to a diodepb.Entity
 func (e *ClusterGroup) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_ClusterGroup{
@@ -166,13 +198,13 @@ func (e *ClusterGroup) ConvertToProtoEntity() *diodepb.Entity {
 
 // ClusterType is based on diodepb.ClusterType
 type ClusterType struct {
-	Name        *string
-	Slug        *string
-	Description *string
-	Tags        []*Tag
+	Name        *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageClusterType converts a ClusterType to a diodepb.ClusterType
+// ConvertToProtoMessage converts a ClusterType to a diodepb.ClusterType
 func (e *ClusterType) ConvertToProtoMessage() proto.Message {
 	return &diodepb.ClusterType{
 		Name:        e.GetName(),
@@ -215,7 +247,20 @@ func (e *ClusterType) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityClusterType converts a ClusterType to a diodepb.Entity
+// ClusterTypeFromProto converts a diodepb.ClusterType to a ClusterType, returning nil for nil
+func ClusterTypeFromProto(m *diodepb.ClusterType) *ClusterType {
+	if m == nil {
+		return nil
+	}
+	return &ClusterType{
+		Name:        nonZeroPtr(m.GetName()),
+		Slug:        nonZeroPtr(m.GetSlug()),
+		Description: clonePtr(m.Description),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a ClusterType to a diodepb.Entity
 func (e *ClusterType) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_ClusterType{
@@ -226,23 +271,23 @@ func (e *ClusterType) ConvertToProtoEntity() *diodepb.Entity {
 
 // Device is based on diodepb.Device
 type Device struct {
-	Name        *string
-	DeviceFqdn  *string
-	DeviceType  *DeviceType
-	Role        *Role
-	Platform    *Platform
-	Serial      *string
-	Site        *Site
-	AssetTag    *string
-	Status      *string
-	Description *string
-	Comments    *string
-	Tags        []*Tag
-	PrimaryIp4  *IPAddress
-	PrimaryIp6  *IPAddress
-}
-
-// ConvertToProtoMessageDevice converts a Device to a diodepb.Device
+	Name        *string     `json:"name,omitempty" yaml:"name,omitempty"`
+	DeviceFqdn  *string     `json:"device_fqdn,omitempty" yaml:"device_fqdn,omitempty"`
+	DeviceType  *DeviceType `json:"device_type,omitempty" yaml:"device_type,omitempty"`
+	Role        *Role       `json:"role,omitempty" yaml:"role,omitempty"`
+	Platform    *Platform   `json:"platform,omitempty" yaml:"platform,omitempty"`
+	Serial      *string     `json:"serial,omitempty" yaml:"serial,omitempty"`
+	Site        *Site       `json:"site,omitempty" yaml:"site,omitempty"`
+	AssetTag    *string     `json:"asset_tag,omitempty" yaml:"asset_tag,omitempty"`
+	Status      *string     `json:"status,omitempty" yaml:"status,omitempty"`
+	Description *string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments    *string     `json:"comments,omitempty" yaml:"comments,omitempty"`
+	Tags        []*Tag      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	PrimaryIp4  *IPAddress  `json:"primary_ip4,omitempty" yaml:"primary_ip4,omitempty"`
+	PrimaryIp6  *IPAddress  `json:"primary_ip6,omitempty" yaml:"primary_ip6,omitempty"`
+}
+
+// ConvertToProtoMessage converts a Device to a diodepb.Device
 func (e *Device) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Device{
 		Name:        e.GetName(),
@@ -375,7 +420,30 @@ func (e *Device) GetPrimaryIp6() *diodepb.IPAddress {
 	return nil
 }
 
-// ConvertToProtoEntityDevice converts a Device to a diodepb.Entity
+// DeviceFromProto converts a diodepb.Device to a Device, returning nil for nil
+func DeviceFromProto(m *diodepb.Device) *Device {
+	if m == nil {
+		return nil
+	}
+	return &Device{
+		Name:        nonZeroPtr(m.GetName()),
+		DeviceFqdn:  clonePtr(m.DeviceFqdn),
+		DeviceType:  DeviceTypeFromProto(m.GetDeviceType()),
+		Role:        RoleFromProto(m.GetRole()),
+		Platform:    PlatformFromProto(m.GetPlatform()),
+		Serial:      clonePtr(m.Serial),
+		Site:        SiteFromProto(m.GetSite()),
+		AssetTag:    clonePtr(m.AssetTag),
+		Status:      nonZeroPtr(m.GetStatus()),
+		Description: clonePtr(m.Description),
+		Comments:    clonePtr(m.Comments),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+		PrimaryIp4:  IPAddressFromProto(m.GetPrimaryIp4()),
+		PrimaryIp6:  IPAddressFromProto(m.GetPrimaryIp6()),
+	}
+}
+
+// ConvertToProtoEntity converts a Device to a diodepb.Entity
 func (e *Device) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Device{
@@ -386,16 +454,16 @@ func (e *Device) ConvertToProtoEntity() *diodepb.Entity {
 
 // DeviceType is based on diodepb.DeviceType
 type DeviceType struct {
-	Model        *string
-	Slug         *string
-	Manufacturer *Manufacturer
-	Description  *string
-	Comments     *string
-	PartNumber   *string
-	Tags         []*Tag
+	Model        *string       `json:"model,omitempty" yaml:"model,omitempty"`
+	Slug         *string       `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Manufacturer *Manufacturer `json:"manufacturer,omitempty" yaml:"manufacturer,omitempty"`
+	Description  *string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments     *string       `json:"comments,omitempty" yaml:"comments,omitempty"`
+	PartNumber   *string       `json:"part_number,omitempty" yaml:"part_number,omitempty"`
+	Tags         []*Tag        `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageDeviceType converts a DeviceType to a diodepb.DeviceType
+// ConvertToProtoMessage converts a DeviceType to a diodepb.DeviceType
 func (e *DeviceType) ConvertToProtoMessage() proto.Message {
 	return &diodepb.DeviceType{
 		Model:        e.GetModel(),
@@ -465,7 +533,23 @@ func (e *DeviceType) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityDeviceType converts a DeviceType to a diodepb.Entity
+// DeviceTypeFromProto converts a diodepb.DeviceType to a DeviceType, returning nil for nil
+func DeviceTypeFromProto(m *diodepb.DeviceType) *DeviceType {
+	if m == nil {
+		return nil
+	}
+	return &DeviceType{
+		Model:        nonZeroPtr(m.GetModel()),
+		Slug:         nonZeroPtr(m.GetSlug()),
+		Manufacturer: ManufacturerFromProto(m.GetManufacturer()),
+		Description:  clonePtr(m.Description),
+		Comments:     clonePtr(m.Comments),
+		PartNumber:   clonePtr(m.PartNumber),
+		Tags:         fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a DeviceType to a diodepb.Entity
 func (e *DeviceType) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_DeviceType{
@@ -476,28 +560,31 @@ func (e *DeviceType) ConvertToProtoEntity() *diodepb.Entity {
 
 // IPAddress is based on diodepb.IPAddress
 type IPAddress struct {
-	Address        *string
-	AssignedObject *Interface
-	Status         *string
-	Role           *string
-	DnsName        *string
-	Description    *string
-	Comments       *string
-	Tags           []*Tag
+	Address        *string    `json:"address,omitempty" yaml:"address,omitempty"`
+	AssignedObject *Interface `json:"assigned_object,omitempty" yaml:"assigned_object,omitempty"`
+	Status         *string    `json:"status,omitempty" yaml:"status,omitempty"`
+	Role           *string    `json:"role,omitempty" yaml:"role,omitempty"`
+	DnsName        *string    `json:"dns_name,omitempty" yaml:"dns_name,omitempty"`
+	Description    *string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments       *string    `json:"comments,omitempty" yaml:"comments,omitempty"`
+	Tags           []*Tag     `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageIPAddress converts a IPAddress to a diodepb.IPAddress
+// ConvertToProtoMessage converts a IPAddress to a diodepb.IPAddress
 func (e *IPAddress) ConvertToProtoMessage() proto.Message {
-	return &diodepb.IPAddress{
-		Address:        e.GetAddress(),
-		AssignedObject: e.GetAssignedObject(),
-		Status:         e.GetStatus(),
-		Role:           e.GetRole(),
-		DnsName:        e.GetDnsName(),
-		Description:    e.GetDescription(),
-		Comments:       e.GetComments(),
-		Tags:           e.GetTags(),
+	m := &diodepb.IPAddress{
+		Address:     e.GetAddress(),
+		Status:      e.GetStatus(),
+		Role:        e.GetRole(),
+		DnsName:     e.GetDnsName(),
+		Description: e.GetDescription(),
+		Comments:    e.GetComments(),
+		Tags:        e.GetTags(),
+	}
+	if e.AssignedObject != nil {
+		m.AssignedObject = e.GetAssignedObject()
 	}
+	return m
 }
 
 // GetAddress returns the Address field
@@ -567,7 +654,24 @@ func (e *IPAddress) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityIPAddress converts a IPAddress to a diodepb.Entity
+// IPAddressFromProto converts a diodepb.IPAddress to a IPAddress, returning nil for nil
+func IPAddressFromProto(m *diodepb.IPAddress) *IPAddress {
+	if m == nil {
+		return nil
+	}
+	return &IPAddress{
+		Address:        nonZeroPtr(m.GetAddress()),
+		AssignedObject: InterfaceFromProto(m.GetInterface()),
+		Status:         nonZeroPtr(m.GetStatus()),
+		Role:           nonZeroPtr(m.GetRole()),
+		DnsName:        clonePtr(m.DnsName),
+		Description:    clonePtr(m.Description),
+		Comments:       clonePtr(m.Comments),
+		Tags:           fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a IPAddress to a diodepb.Entity
 func (e *IPAddress) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_IpAddress{
@@ -578,23 +682,23 @@ func (e *IPAddress) ConvertToProtoEntity() *diodepb.Entity {
 
 // Interface is based on diodepb.Interface
 type Interface struct {
-	Device        *Device
-	Name          *string
-	Label         *string
-	Type          *string
-	Enabled       *bool
-	Mtu           *int32
-	MacAddress    *string
-	Speed         *int32
-	Wwn           *string
-	MgmtOnly      *bool
-	Description   *string
-	MarkConnected *bool
-	Mode          *string
-	Tags          []*Tag
-}
-
-// ConvertToProtoMessageInterface converts a Interface to a diodepb.Interface
+	Device        *Device `json:"device,omitempty" yaml:"device,omitempty"`
+	Name          *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Label         *string `json:"label,omitempty" yaml:"label,omitempty"`
+	Type          *string `json:"type,omitempty" yaml:"type,omitempty"`
+	Enabled       *bool   `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Mtu           *int32  `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+	MacAddress    *string `json:"mac_address,omitempty" yaml:"mac_address,omitempty"`
+	Speed         *int32  `json:"speed,omitempty" yaml:"speed,omitempty"`
+	Wwn           *string `json:"wwn,omitempty" yaml:"wwn,omitempty"`
+	MgmtOnly      *bool   `json:"mgmt_only,omitempty" yaml:"mgmt_only,omitempty"`
+	Description   *string `json:"description,omitempty" yaml:"description,omitempty"`
+	MarkConnected *bool   `json:"mark_connected,omitempty" yaml:"mark_connected,omitempty"`
+	Mode          *string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Tags          []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// ConvertToProtoMessage converts a Interface to a diodepb.Interface
 func (e *Interface) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Interface{
 		Device:        e.GetDevice(),
@@ -727,7 +831,30 @@ func (e *Interface) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityInterface converts a Interface to a diodepb.Entity
+// InterfaceFromProto converts a diodepb.Interface to a Interface, returning nil for nil
+func InterfaceFromProto(m *diodepb.Interface) *Interface {
+	if m == nil {
+		return nil
+	}
+	return &Interface{
+		Device:        DeviceFromProto(m.GetDevice()),
+		Name:          nonZeroPtr(m.GetName()),
+		Label:         clonePtr(m.Label),
+		Type:          nonZeroPtr(m.GetType()),
+		Enabled:       clonePtr(m.Enabled),
+		Mtu:           clonePtr(m.Mtu),
+		MacAddress:    clonePtr(m.MacAddress),
+		Speed:         clonePtr(m.Speed),
+		Wwn:           clonePtr(m.Wwn),
+		MgmtOnly:      clonePtr(m.MgmtOnly),
+		Description:   clonePtr(m.Description),
+		MarkConnected: clonePtr(m.MarkConnected),
+		Mode:          nonZeroPtr(m.GetMode()),
+		Tags:          fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Interface to a diodepb.Entity
 func (e *Interface) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Interface{
@@ -738,13 +865,13 @@ func (e *Interface) ConvertToProtoEntity() *diodepb.Entity {
 
 // Manufacturer is based on diodepb.Manufacturer
 type Manufacturer struct {
-	Name        *string
-	Slug        *string
-	Description *string
-	Tags        []*Tag
+	Name        *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageManufacturer converts a Manufacturer to a diodepb.Manufacturer
+// ConvertToProtoMessage converts a Manufacturer to a diodepb.Manufacturer
 func (e *Manufacturer) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Manufacturer{
 		Name:        e.GetName(),
@@ -787,7 +914,20 @@ func (e *Manufacturer) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityManufacturer converts a Manufacturer to a diodepb.Entity
+// ManufacturerFromProto converts a diodepb.Manufacturer to a Manufacturer, returning nil for nil
+func ManufacturerFromProto(m *diodepb.Manufacturer) *Manufacturer {
+	if m == nil {
+		return nil
+	}
+	return &Manufacturer{
+		Name:        nonZeroPtr(m.GetName()),
+		Slug:        nonZeroPtr(m.GetSlug()),
+		Description: clonePtr(m.Description),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Manufacturer to a diodepb.Entity
 func (e *Manufacturer) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Manufacturer{
@@ -798,14 +938,14 @@ func (e *Manufacturer) ConvertToProtoEntity() *diodepb.Entity {
 
 // Platform is based on diodepb.Platform
 type Platform struct {
-	Name         *string
-	Slug         *string
-	Manufacturer *Manufacturer
-	Description  *string
-	Tags         []*Tag
+	Name         *string       `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug         *string       `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Manufacturer *Manufacturer `json:"manufacturer,omitempty" yaml:"manufacturer,omitempty"`
+	Description  *string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags         []*Tag        `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessagePlatform converts a Platform to a diodepb.Platform
+// ConvertToProtoMessage converts a Platform to a diodepb.Platform
 func (e *Platform) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Platform{
 		Name:         e.GetName(),
@@ -857,7 +997,21 @@ func (e *Platform) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityPlatform converts a Platform to a diodepb.Entity
+// PlatformFromProto converts a diodepb.Platform to a Platform, returning nil for nil
+func PlatformFromProto(m *diodepb.Platform) *Platform {
+	if m == nil {
+		return nil
+	}
+	return &Platform{
+		Name:         nonZeroPtr(m.GetName()),
+		Slug:         nonZeroPtr(m.GetSlug()),
+		Manufacturer: ManufacturerFromProto(m.GetManufacturer()),
+		Description:  clonePtr(m.Description),
+		Tags:         fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Platform to a diodepb.Entity
 func (e *Platform) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Platform{
@@ -868,17 +1022,17 @@ func (e *Platform) ConvertToProtoEntity() *diodepb.Entity {
 
 // Prefix is based on diodepb.Prefix
 type Prefix struct {
-	Prefix       *string
-	Site         *Site
-	Status       *string
-	IsPool       *bool
-	MarkUtilized *bool
-	Description  *string
-	Comments     *string
-	Tags         []*Tag
+	Prefix       *string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Site         *Site   `json:"site,omitempty" yaml:"site,omitempty"`
+	Status       *string `json:"status,omitempty" yaml:"status,omitempty"`
+	IsPool       *bool   `json:"is_pool,omitempty" yaml:"is_pool,omitempty"`
+	MarkUtilized *bool   `json:"mark_utilized,omitempty" yaml:"mark_utilized,omitempty"`
+	Description  *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments     *string `json:"comments,omitempty" yaml:"comments,omitempty"`
+	Tags         []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessagePrefix converts a Prefix to a diodepb.Prefix
+// ConvertToProtoMessage converts a Prefix to a diodepb.Prefix
 func (e *Prefix) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Prefix{
 		Prefix:       e.GetPrefix(),
@@ -957,7 +1111,24 @@ func (e *Prefix) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityPrefix converts a Prefix to a diodepb.Entity
+// PrefixFromProto converts a diodepb.Prefix to a Prefix, returning nil for nil
+func PrefixFromProto(m *diodepb.Prefix) *Prefix {
+	if m == nil {
+		return nil
+	}
+	return &Prefix{
+		Prefix:       nonZeroPtr(m.GetPrefix()),
+		Site:         SiteFromProto(m.GetSite()),
+		Status:       nonZeroPtr(m.GetStatus()),
+		IsPool:       clonePtr(m.IsPool),
+		MarkUtilized: clonePtr(m.MarkUtilized),
+		Description:  clonePtr(m.Description),
+		Comments:     clonePtr(m.Comments),
+		Tags:         fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Prefix to a diodepb.Entity
 func (e *Prefix) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Prefix{
@@ -968,14 +1139,14 @@ func (e *Prefix) ConvertToProtoEntity() *diodepb.Entity {
 
 // Role is based on diodepb.Role
 type Role struct {
-	Name        *string
-	Slug        *string
-	Color       *string
-	Description *string
-	Tags        []*Tag
+	Name        *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Color       *string `json:"color,omitempty" yaml:"color,omitempty"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageRole converts a Role to a diodepb.Role
+// ConvertToProtoMessage converts a Role to a diodepb.Role
 func (e *Role) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Role{
 		Name:        e.GetName(),
@@ -1027,7 +1198,21 @@ func (e *Role) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityRole converts a Role to a diodepb.Entity
+// RoleFromProto converts a diodepb.Role to a Role, returning nil for nil
+func RoleFromProto(m *diodepb.Role) *Role {
+	if m == nil {
+		return nil
+	}
+	return &Role{
+		Name:        nonZeroPtr(m.GetName()),
+		Slug:        nonZeroPtr(m.GetSlug()),
+		Color:       nonZeroPtr(m.GetColor()),
+		Description: clonePtr(m.Description),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Role to a diodepb.Entity
 func (e *Role) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_DeviceRole{
@@ -1038,17 +1223,17 @@ func (e *Role) ConvertToProtoEntity() *diodepb.Entity {
 
 // Site is based on diodepb.Site
 type Site struct {
-	Name        *string
-	Slug        *string
-	Status      *string
-	Facility    *string
-	TimeZone    *string
-	Description *string
-	Comments    *string
-	Tags        []*Tag
+	Name        *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Status      *string `json:"status,omitempty" yaml:"status,omitempty"`
+	Facility    *string `json:"facility,omitempty" yaml:"facility,omitempty"`
+	TimeZone    *string `json:"time_zone,omitempty" yaml:"time_zone,omitempty"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments    *string `json:"comments,omitempty" yaml:"comments,omitempty"`
+	Tags        []*Tag  `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageSite converts a Site to a diodepb.Site
+// ConvertToProtoMessage converts a Site to a diodepb.Site
 func (e *Site) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Site{
 		Name:        e.GetName(),
@@ -1127,7 +1312,24 @@ func (e *Site) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntitySite converts a Site to a diodepb.Entity
+// SiteFromProto converts a diodepb.Site to a Site, returning nil for nil
+func SiteFromProto(m *diodepb.Site) *Site {
+	if m == nil {
+		return nil
+	}
+	return &Site{
+		Name:        nonZeroPtr(m.GetName()),
+		Slug:        nonZeroPtr(m.GetSlug()),
+		Status:      nonZeroPtr(m.GetStatus()),
+		Facility:    clonePtr(m.Facility),
+		TimeZone:    clonePtr(m.TimeZone),
+		Description: clonePtr(m.Description),
+		Comments:    clonePtr(m.Comments),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a Site to a diodepb.Entity
 func (e *Site) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Site{
@@ -1137,13 +1339,16 @@ func (e *Site) ConvertToProtoEntity() *diodepb.Entity {
 }
 
 // Tag is based on diodepb.Tag
+//
+// Tag is not an Entity as diodepb.Entity has no Tag member, it is ingested with the entities
+// referencing it.
 type Tag struct {
-	Name  *string
-	Slug  *string
-	Color *string
+	Name  *string `json:"name,omitempty" yaml:"name,omitempty"`
+	Slug  *string `json:"slug,omitempty" yaml:"slug,omitempty"`
+	Color *string `json:"color,omitempty" yaml:"color,omitempty"`
 }
 
-// ConvertToProtoMessageTag converts a Tag to a diodepb.Tag
+// ConvertToProtoMessage converts a Tag to a diodepb.Tag
 func (e *Tag) ConvertToProtoMessage() proto.Message {
 	return &diodepb.Tag{
 		Name:  e.GetName(),
@@ -1176,18 +1381,30 @@ func (e *Tag) GetColor() string {
 	return ""
 }
 
+// TagFromProto converts a diodepb.Tag to a Tag, returning nil for nil
+func TagFromProto(m *diodepb.Tag) *Tag {
+	if m == nil {
+		return nil
+	}
+	return &Tag{
+		Name:  nonZeroPtr(m.GetName()),
+		Slug:  nonZeroPtr(m.GetSlug()),
+		Color: nonZeroPtr(m.GetColor()),
+	}
+}
+
 // VMInterface is based on diodepb.VMInterface
 type VMInterface struct {
-	VirtualMachine *VirtualMachine
-	Name           *string
-	Enabled        *bool
-	Mtu            *int32
-	MacAddress     *string
-	Description    *string
-	Tags           []*Tag
+	VirtualMachine *VirtualMachine `json:"virtual_machine,omitempty" yaml:"virtual_machine,omitempty"`
+	Name           *string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Enabled        *bool           `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Mtu            *int32          `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+	MacAddress     *string         `json:"mac_address,omitempty" yaml:"mac_address,omitempty"`
+	Description    *string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags           []*Tag          `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageVMInterface converts a VMInterface to a diodepb.VMInterface
+// ConvertToProtoMessage converts a VMInterface to a diodepb.VMInterface
 func (e *VMInterface) ConvertToProtoMessage() proto.Message {
 	return &diodepb.VMInterface{
 		VirtualMachine: e.GetVirtualMachine(),
@@ -1257,7 +1474,23 @@ func (e *VMInterface) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityVMInterface converts a VMInterface to a diodepb.Entity
+// VMInterfaceFromProto converts a diodepb.VMInterface to a VMInterface, returning nil for nil
+func VMInterfaceFromProto(m *diodepb.VMInterface) *VMInterface {
+	if m == nil {
+		return nil
+	}
+	return &VMInterface{
+		VirtualMachine: VirtualMachineFromProto(m.GetVirtualMachine()),
+		Name:           nonZeroPtr(m.GetName()),
+		Enabled:        clonePtr(m.Enabled),
+		Mtu:            clonePtr(m.Mtu),
+		MacAddress:     clonePtr(m.MacAddress),
+		Description:    clonePtr(m.Description),
+		Tags:           fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a VMInterface to a diodepb.Entity
 func (e *VMInterface) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_Vminterface{
@@ -1268,14 +1501,14 @@ func (e *VMInterface) ConvertToProtoEntity() *diodepb.Entity {
 
 // VirtualDisk is based on diodepb.VirtualDisk
 type VirtualDisk struct {
-	VirtualMachine *VirtualMachine
-	Name           *string
-	Size           *int32
-	Description    *string
-	Tags           []*Tag
+	VirtualMachine *VirtualMachine `json:"virtual_machine,omitempty" yaml:"virtual_machine,omitempty"`
+	Name           *string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Size           *int32          `json:"size,omitempty" yaml:"size,omitempty"`
+	Description    *string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags           []*Tag          `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
-// ConvertToProtoMessageVirtualDisk converts a VirtualDisk to a diodepb.VirtualDisk
+// ConvertToProtoMessage converts a VirtualDisk to a diodepb.VirtualDisk
 func (e *VirtualDisk) ConvertToProtoMessage() proto.Message {
 	return &diodepb.VirtualDisk{
 		VirtualMachine: e.GetVirtualMachine(),
@@ -1327,7 +1560,21 @@ func (e *VirtualDisk) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityVirtualDisk converts a VirtualDisk to a diodepb.Entity
+// VirtualDiskFromProto converts a diodepb.VirtualDisk to a VirtualDisk, returning nil for nil
+func VirtualDiskFromProto(m *diodepb.VirtualDisk) *VirtualDisk {
+	if m == nil {
+		return nil
+	}
+	return &VirtualDisk{
+		VirtualMachine: VirtualMachineFromProto(m.GetVirtualMachine()),
+		Name:           nonZeroPtr(m.GetName()),
+		Size:           nonZeroPtr(m.GetSize()),
+		Description:    clonePtr(m.Description),
+		Tags:           fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a VirtualDisk to a diodepb.Entity
 func (e *VirtualDisk) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_VirtualDisk{
@@ -1338,24 +1585,24 @@ func (e *VirtualDisk) ConvertToProtoEntity() *diodepb.Entity {
 
 // VirtualMachine is based on diodepb.VirtualMachine
 type VirtualMachine struct {
-	Name        *string
-	Status      *string
-	Site        *Site
-	Cluster     *Cluster
-	Role        *Role
-	Device      *Device
-	Platform    *Platform
-	PrimaryIp4  *IPAddress
-	PrimaryIp6  *IPAddress
-	Vcpus       *int32
-	Memory      *int32
-	Disk        *int32
-	Description *string
-	Comments    *string
-	Tags        []*Tag
-}
-
-// ConvertToProtoMessageVirtualMachine converts a VirtualMachine to a diodepb.VirtualMachine
+	Name        *string    `json:"name,omitempty" yaml:"name,omitempty"`
+	Status      *string    `json:"status,omitempty" yaml:"status,omitempty"`
+	Site        *Site      `json:"site,omitempty" yaml:"site,omitempty"`
+	Cluster     *Cluster   `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Role        *Role      `json:"role,omitempty" yaml:"role,omitempty"`
+	Device      *Device    `json:"device,omitempty" yaml:"device,omitempty"`
+	Platform    *Platform  `json:"platform,omitempty" yaml:"platform,omitempty"`
+	PrimaryIp4  *IPAddress `json:"primary_ip4,omitempty" yaml:"primary_ip4,omitempty"`
+	PrimaryIp6  *IPAddress `json:"primary_ip6,omitempty" yaml:"primary_ip6,omitempty"`
+	Vcpus       *int32     `json:"vcpus,omitempty" yaml:"vcpus,omitempty"`
+	Memory      *int32     `json:"memory,omitempty" yaml:"memory,omitempty"`
+	Disk        *int32     `json:"disk,omitempty" yaml:"disk,omitempty"`
+	Description *string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments    *string    `json:"comments,omitempty" yaml:"comments,omitempty"`
+	Tags        []*Tag     `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// ConvertToProtoMessage converts a VirtualMachine to a diodepb.VirtualMachine
 func (e *VirtualMachine) ConvertToProtoMessage() proto.Message {
 	return &diodepb.VirtualMachine{
 		Name:        e.GetName(),
@@ -1497,7 +1744,31 @@ func (e *VirtualMachine) GetTags() []*diodepb.Tag {
 	return tags
 }
 
-// ConvertToProtoEntityVirtualMachine converts a VirtualMachine to a diodepb.Entity
+// VirtualMachineFromProto converts a diodepb.VirtualMachine to a VirtualMachine, returning nil for nil
+func VirtualMachineFromProto(m *diodepb.VirtualMachine) *VirtualMachine {
+	if m == nil {
+		return nil
+	}
+	return &VirtualMachine{
+		Name:        nonZeroPtr(m.GetName()),
+		Status:      nonZeroPtr(m.GetStatus()),
+		Site:        SiteFromProto(m.GetSite()),
+		Cluster:     ClusterFromProto(m.GetCluster()),
+		Role:        RoleFromProto(m.GetRole()),
+		Device:      DeviceFromProto(m.GetDevice()),
+		Platform:    PlatformFromProto(m.GetPlatform()),
+		PrimaryIp4:  IPAddressFromProto(m.GetPrimaryIp4()),
+		PrimaryIp6:  IPAddressFromProto(m.GetPrimaryIp6()),
+		Vcpus:       clonePtr(m.Vcpus),
+		Memory:      clonePtr(m.Memory),
+		Disk:        clonePtr(m.Disk),
+		Description: clonePtr(m.Description),
+		Comments:    clonePtr(m.Comments),
+		Tags:        fromProtoSlice(m.GetTags(), TagFromProto),
+	}
+}
+
+// ConvertToProtoEntity converts a VirtualMachine to a diodepb.Entity
 func (e *VirtualMachine) ConvertToProtoEntity() *diodepb.Entity {
 	return &diodepb.Entity{
 		Entity: &diodepb.Entity_VirtualMachine{
@@ -1505,3 +1776,125 @@ func (e *VirtualMachine) ConvertToProtoEntity() *diodepb.Entity {
 		},
 	}
 }
+
+// entityFromProto converts the message set in a diodepb.Entity to its entity, returning nil if none is set
+func entityFromProto(m *diodepb.Entity) Entity {
+	switch e := m.GetEntity().(type) {
+	case *diodepb.Entity_Cluster:
+		if e.Cluster != nil {
+			return ClusterFromProto(e.Cluster)
+		}
+	case *diodepb.Entity_ClusterGroup:
+		if e.ClusterGroup != nil {
+			return ClusterGroupFromProto(e.ClusterGroup)
+		}
+	case *diodepb.Entity_ClusterType:
+		if e.ClusterType != nil {
+			return ClusterTypeFromProto(e.ClusterType)
+		}
+	case *diodepb.Entity_Device:
+		if e.Device != nil {
+			return DeviceFromProto(e.Device)
+		}
+	case *diodepb.Entity_DeviceType:
+		if e.DeviceType != nil {
+			return DeviceTypeFromProto(e.DeviceType)
+		}
+	case *diodepb.Entity_IpAddress:
+		if e.IpAddress != nil {
+			return IPAddressFromProto(e.IpAddress)
+		}
+	case *diodepb.Entity_Interface:
+		if e.Interface != nil {
+			return InterfaceFromProto(e.Interface)
+		}
+	case *diodepb.Entity_Manufacturer:
+		if e.Manufacturer != nil {
+			return ManufacturerFromProto(e.Manufacturer)
+		}
+	case *diodepb.Entity_Platform:
+		if e.Platform != nil {
+			return PlatformFromProto(e.Platform)
+		}
+	case *diodepb.Entity_Prefix:
+		if e.Prefix != nil {
+			return PrefixFromProto(e.Prefix)
+		}
+	case *diodepb.Entity_DeviceRole:
+		if e.DeviceRole != nil {
+			return RoleFromProto(e.DeviceRole)
+		}
+	case *diodepb.Entity_Site:
+		if e.Site != nil {
+			return SiteFromProto(e.Site)
+		}
+	case *diodepb.Entity_Vminterface:
+		if e.Vminterface != nil {
+			return VMInterfaceFromProto(e.Vminterface)
+		}
+	case *diodepb.Entity_VirtualDisk:
+		if e.VirtualDisk != nil {
+			return VirtualDiskFromProto(e.VirtualDisk)
+		}
+	case *diodepb.Entity_VirtualMachine:
+		if e.VirtualMachine != nil {
+			return VirtualMachineFromProto(e.VirtualMachine)
+		}
+	}
+	return nil
+}
+
+// entityTypes maps the Entity oneof field names to constructors of the matching entities
+var entityTypes = map[string]func() Entity{
+	"site":            func() Entity { return &Site{} },
+	"platform":        func() Entity { return &Platform{} },
+	"manufacturer":    func() Entity { return &Manufacturer{} },
+	"device":          func() Entity { return &Device{} },
+	"device_role":     func() Entity { return &Role{} },
+	"device_type":     func() Entity { return &DeviceType{} },
+	"interface":       func() Entity { return &Interface{} },
+	"ip_address":      func() Entity { return &IPAddress{} },
+	"prefix":          func() Entity { return &Prefix{} },
+	"cluster_group":   func() Entity { return &ClusterGroup{} },
+	"cluster_type":    func() Entity { return &ClusterType{} },
+	"cluster":         func() Entity { return &Cluster{} },
+	"virtual_machine": func() Entity { return &VirtualMachine{} },
+	"vminterface":     func() Entity { return &VMInterface{} },
+	"virtual_disk":    func() Entity { return &VirtualDisk{} },
+}
+
+// entityTimestampKey is the key of the discovery timestamp in the objects wrapping the entities of an EntityList
+const entityTimestampKey = "timestamp"
+
+// TimestampedEntity is an Entity with the timestamp of its discovery at source
+type TimestampedEntity struct {
+	Entity
+
+	// Timestamp is the time the entity was discovered at source, zero value leaves it unset
+	Timestamp time.Time
+}
+
+// Timestamped wraps an entity with the timestamp of its discovery at source
+func Timestamped(entity Entity, ts time.Time) *TimestampedEntity {
+	return &TimestampedEntity{
+		Entity:    entity,
+		Timestamp: ts,
+	}
+}
+
+// ConvertToProtoEntity converts a TimestampedEntity to a diodepb.Entity with the discovery timestamp set
+func (e *TimestampedEntity) ConvertToProtoEntity() *diodepb.Entity {
+	entity := e.Entity.ConvertToProtoEntity()
+	if !e.Timestamp.IsZero() {
+		entity.Timestamp = timestamppb.New(e.Timestamp)
+	}
+	return entity
+}
+
+// timestampedFromProto wraps the entity in a TimestampedEntity if the diodepb.Entity has a discovery timestamp
+func timestampedFromProto(m *diodepb.Entity, entity Entity) Entity {
+	if ts := m.GetTimestamp(); ts != nil {
+		return Timestamped(entity, ts.AsTime())
+	}
+	return entity
+}
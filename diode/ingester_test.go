@@ -1,9 +1,15 @@
 package diode
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
 )
@@ -125,8 +131,7 @@ func TestDeviceMethods(t *testing.T) {
 			name:   "GetPrimaryIp4",
 			device: &Device{PrimaryIp4: &IPAddress{Address: String("192.168.1.1")}},
 			expected: &diodepb.IPAddress{
-				Address:        "192.168.1.1",
-				AssignedObject: (*diodepb.IPAddress_Interface)(nil),
+				Address: "192.168.1.1",
 			},
 			method: func(d *Device) interface{} {
 				return d.GetPrimaryIp4()
@@ -136,8 +141,7 @@ func TestDeviceMethods(t *testing.T) {
 			name:   "GetPrimaryIp6",
 			device: &Device{PrimaryIp6: &IPAddress{Address: String("::1")}},
 			expected: &diodepb.IPAddress{
-				Address:        "::1",
-				AssignedObject: (*diodepb.IPAddress_Interface)(nil),
+				Address: "::1",
 			},
 			method: func(d *Device) interface{} {
 				return d.GetPrimaryIp6()
@@ -281,8 +285,7 @@ func TestIPAddressMethods(t *testing.T) {
 			name:      "ConvertToProtoMessage",
 			ipAddress: &IPAddress{Address: String("192.168.1.1")},
 			expected: &diodepb.IPAddress{
-				Address:        "192.168.1.1",
-				AssignedObject: (*diodepb.IPAddress_Interface)(nil),
+				Address: "192.168.1.1",
 			},
 			method: func(ip *IPAddress) interface{} {
 				return ip.ConvertToProtoMessage()
@@ -360,8 +363,7 @@ func TestIPAddressMethods(t *testing.T) {
 			expected: &diodepb.Entity{
 				Entity: &diodepb.Entity_IpAddress{
 					IpAddress: &diodepb.IPAddress{
-						Address:        "192.168.1.1",
-						AssignedObject: (*diodepb.IPAddress_Interface)(nil),
+						Address: "192.168.1.1",
 					},
 				},
 			},
@@ -1315,8 +1317,7 @@ func TestVirtualMachineMethods(t *testing.T) {
 			name:           "GetPrimaryIp4",
 			virtualMachine: &VirtualMachine{PrimaryIp4: &IPAddress{Address: String("192.168.1.1")}},
 			expected: &diodepb.IPAddress{
-				Address:        "192.168.1.1",
-				AssignedObject: (*diodepb.IPAddress_Interface)(nil),
+				Address: "192.168.1.1",
 			},
 			method: func(vm *VirtualMachine) interface{} {
 				return vm.GetPrimaryIp4()
@@ -1326,8 +1327,7 @@ func TestVirtualMachineMethods(t *testing.T) {
 			name:           "GetPrimaryIp6",
 			virtualMachine: &VirtualMachine{PrimaryIp6: &IPAddress{Address: String("::1")}},
 			expected: &diodepb.IPAddress{
-				Address:        "::1",
-				AssignedObject: (*diodepb.IPAddress_Interface)(nil),
+				Address: "::1",
 			},
 			method: func(vm *VirtualMachine) interface{} {
 				return vm.GetPrimaryIp6()
@@ -1553,3 +1553,197 @@ func TestVirtualDiskMethods(t *testing.T) {
 		})
 	}
 }
+
+// randomMessage fills the fields of a message with random values, leaving nested messages unset beyond the given depth
+func randomMessage(r *rand.Rand, m protoreflect.Message, depth int) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			// set at most one member of the oneof
+			if oneof.Fields().Get(0) != fd || r.Intn(2) == 0 {
+				continue
+			}
+			fd = oneof.Fields().Get(r.Intn(oneof.Fields().Len()))
+		} else if r.Intn(4) == 0 {
+			continue
+		}
+
+		switch {
+		case fd.Kind() == protoreflect.MessageKind:
+			if depth == 0 {
+				continue
+			}
+			if fd.IsList() {
+				list := m.Mutable(fd).List()
+				for n := r.Intn(3); n > 0; n-- {
+					v := list.NewElement()
+					randomMessage(r, v.Message(), depth-1)
+					list.Append(v)
+				}
+				continue
+			}
+			randomMessage(r, m.Mutable(fd).Message(), depth-1)
+		case fd.Kind() == protoreflect.StringKind:
+			m.Set(fd, protoreflect.ValueOfString(fmt.Sprintf("value-%d", r.Intn(100))))
+		case fd.Kind() == protoreflect.BoolKind:
+			m.Set(fd, protoreflect.ValueOfBool(r.Intn(2) == 0))
+		case fd.Kind() == protoreflect.Int32Kind:
+			m.Set(fd, protoreflect.ValueOfInt32(r.Int31n(10000)-5000))
+		default:
+			panic(fmt.Sprintf("unsupported field kind %s of %s", fd.Kind(), fd.FullName()))
+		}
+	}
+}
+
+func TestFromProtoRoundTrip(t *testing.T) {
+	tests := []struct {
+		desc      string
+		message   proto.Message
+		roundTrip func(proto.Message) proto.Message
+	}{
+		{"Cluster", &diodepb.Cluster{}, func(m proto.Message) proto.Message {
+			return ClusterFromProto(m.(*diodepb.Cluster)).ConvertToProtoMessage()
+		}},
+		{"ClusterGroup", &diodepb.ClusterGroup{}, func(m proto.Message) proto.Message {
+			return ClusterGroupFromProto(m.(*diodepb.ClusterGroup)).ConvertToProtoMessage()
+		}},
+		{"ClusterType", &diodepb.ClusterType{}, func(m proto.Message) proto.Message {
+			return ClusterTypeFromProto(m.(*diodepb.ClusterType)).ConvertToProtoMessage()
+		}},
+		{"Device", &diodepb.Device{}, func(m proto.Message) proto.Message {
+			return DeviceFromProto(m.(*diodepb.Device)).ConvertToProtoMessage()
+		}},
+		{"DeviceType", &diodepb.DeviceType{}, func(m proto.Message) proto.Message {
+			return DeviceTypeFromProto(m.(*diodepb.DeviceType)).ConvertToProtoMessage()
+		}},
+		{"IPAddress", &diodepb.IPAddress{}, func(m proto.Message) proto.Message {
+			return IPAddressFromProto(m.(*diodepb.IPAddress)).ConvertToProtoMessage()
+		}},
+		{"Interface", &diodepb.Interface{}, func(m proto.Message) proto.Message {
+			return InterfaceFromProto(m.(*diodepb.Interface)).ConvertToProtoMessage()
+		}},
+		{"Manufacturer", &diodepb.Manufacturer{}, func(m proto.Message) proto.Message {
+			return ManufacturerFromProto(m.(*diodepb.Manufacturer)).ConvertToProtoMessage()
+		}},
+		{"Platform", &diodepb.Platform{}, func(m proto.Message) proto.Message {
+			return PlatformFromProto(m.(*diodepb.Platform)).ConvertToProtoMessage()
+		}},
+		{"Prefix", &diodepb.Prefix{}, func(m proto.Message) proto.Message {
+			return PrefixFromProto(m.(*diodepb.Prefix)).ConvertToProtoMessage()
+		}},
+		{"Role", &diodepb.Role{}, func(m proto.Message) proto.Message {
+			return RoleFromProto(m.(*diodepb.Role)).ConvertToProtoMessage()
+		}},
+		{"Site", &diodepb.Site{}, func(m proto.Message) proto.Message {
+			return SiteFromProto(m.(*diodepb.Site)).ConvertToProtoMessage()
+		}},
+		{"Tag", &diodepb.Tag{}, func(m proto.Message) proto.Message {
+			return TagFromProto(m.(*diodepb.Tag)).ConvertToProtoMessage()
+		}},
+		{"VMInterface", &diodepb.VMInterface{}, func(m proto.Message) proto.Message {
+			return VMInterfaceFromProto(m.(*diodepb.VMInterface)).ConvertToProtoMessage()
+		}},
+		{"VirtualDisk", &diodepb.VirtualDisk{}, func(m proto.Message) proto.Message {
+			return VirtualDiskFromProto(m.(*diodepb.VirtualDisk)).ConvertToProtoMessage()
+		}},
+		{"VirtualMachine", &diodepb.VirtualMachine{}, func(m proto.Message) proto.Message {
+			return VirtualMachineFromProto(m.(*diodepb.VirtualMachine)).ConvertToProtoMessage()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 200; i++ {
+				m := tt.message.ProtoReflect().New()
+				randomMessage(r, m, 4)
+
+				got := tt.roundTrip(m.Interface())
+				require.True(t, proto.Equal(m.Interface(), got), "want %v, got %v", m.Interface(), got)
+			}
+		})
+	}
+}
+
+func TestFromProtoNil(t *testing.T) {
+	require.Nil(t, DeviceFromProto(nil))
+	require.Nil(t, IPAddressFromProto(nil))
+	require.Nil(t, TagFromProto(nil))
+}
+
+func TestConvertToProtoMessageUnsetOneof(t *testing.T) {
+	m := (&IPAddress{Address: String("192.168.1.1"), Status: String("active"), Role: String("vip")}).
+		ConvertToProtoMessage().(*diodepb.IPAddress)
+
+	require.Nil(t, m.GetAssignedObject())
+	require.Nil(t, m.GetInterface())
+	require.NoError(t, m.ValidateAll())
+}
+
+func TestEntityFromProtoRoundTrip(t *testing.T) {
+	entityOneof := (&diodepb.Entity{}).ProtoReflect().Descriptor().Oneofs().ByName("entity")
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		fd := entityOneof.Fields().Get(r.Intn(entityOneof.Fields().Len()))
+
+		want := &diodepb.Entity{}
+		m := want.ProtoReflect()
+		randomMessage(r, m.Mutable(fd).Message(), 4)
+		if r.Intn(2) == 0 {
+			want.Timestamp = timestamppb.New(time.Unix(r.Int63n(2e9), r.Int63n(1e9)))
+		}
+
+		entity, err := EntityFromProto(want)
+		require.NoError(t, err)
+
+		_, timestamped := entity.(*TimestampedEntity)
+		require.Equal(t, want.Timestamp != nil, timestamped)
+
+		got := entity.ConvertToProtoEntity()
+		require.True(t, proto.Equal(want, got), "want %v, got %v", want, got)
+	}
+}
+
+func TestEntityFromProto(t *testing.T) {
+	tests := []struct {
+		desc       string
+		entity     *diodepb.Entity
+		wantEntity Entity
+		wantErr    string
+	}{
+		{
+			desc:       "device role",
+			entity:     (&Role{Name: String("core")}).ConvertToProtoEntity(),
+			wantEntity: &Role{Name: String("core")},
+		},
+		{
+			desc:       "ip address assigned to an interface",
+			entity:     (&IPAddress{Address: String("10.0.0.1/8"), AssignedObject: &Interface{Name: String("eth0")}}).ConvertToProtoEntity(),
+			wantEntity: &IPAddress{Address: String("10.0.0.1/8"), AssignedObject: &Interface{Name: String("eth0")}},
+		},
+		{
+			desc:    "nil entity",
+			wantErr: "entity is nil",
+		},
+		{
+			desc:    "no type set",
+			entity:  &diodepb.Entity{},
+			wantErr: "entity has no type set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			entity, err := EntityFromProto(tt.entity)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEntity, entity)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package diode
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// LogFormat is the output format of the SDK's default logger
+type LogFormat int
+
+const (
+	// LogFormatJSON writes log records as JSON objects, one per line
+	LogFormatJSON LogFormat = iota
+
+	// LogFormatText writes log records as key=value pairs, one per line
+	LogFormatText
+)
+
+// Log attribute keys used consistently across client operations
+const (
+	logAttrRequestID          = "request_id"
+	logAttrStream             = "stream"
+	logAttrEntityCount        = "entity_count"
+	logAttrRequestCount       = "request_count"
+	logAttrResponseErrorCount = "response_error_count"
+	logAttrChunk              = "chunk"
+	logAttrChunkCount         = "chunk_count"
+	logAttrMaxEntities        = "max_entities"
+	logAttrLimit              = "limit"
+	logAttrChangedCount       = "changed_count"
+	logAttrRemovedCount       = "removed_count"
+	logAttrFullSync           = "full_sync"
+	logAttrAttempt            = "attempt"
+	logAttrBackoff            = "backoff"
+	logAttrTarget             = "target"
+	logAttrPath               = "path"
+	logAttrMethod             = "method"
+	logAttrTLS                = "tls"
+	logAttrSpoolFile          = "spool_file"
+	logAttrCertFile           = "cert_file"
+	logAttrFormat             = "format"
+	logAttrError              = "error"
+)
+
+// WithLogger sets the logger for the client, replacing the SDK's default logger
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *GRPCClient) {
+		c.logger = logger
+	}
+}
+
+// WithLogOutput sets the output of the SDK's default logger, os.Stderr is used by default
+func WithLogOutput(w io.Writer) ClientOption {
+	return func(c *GRPCClient) {
+		c.logOutput = w
+	}
+}
+
+// WithLogFormat sets the format of the SDK's default logger, JSON is used by default
+func WithLogFormat(format LogFormat) ClientOption {
+	return func(c *GRPCClient) {
+		c.logFormat = format
+	}
+}
+
+// requestLogAttrs returns the log attributes identifying an ingest request followed by the given ones
+func requestLogAttrs(req *diodepb.IngestRequest, args ...any) []any {
+	return append([]any{
+		logAttrRequestID, req.GetId(),
+		logAttrStream, req.GetStream(),
+		logAttrEntityCount, len(req.GetEntities()),
+	}, args...)
+}
+
+// requestsLogAttrs returns the log attributes identifying the ingest requests of an RPC followed by the given ones, the
+// request ID and stream being the ones of the first request
+func requestsLogAttrs(reqs []*diodepb.IngestRequest, args ...any) []any {
+	if len(reqs) == 1 {
+		return requestLogAttrs(reqs[0], args...)
+	}
+
+	var first *diodepb.IngestRequest
+	if len(reqs) > 0 {
+		first = reqs[0]
+	}
+	return append([]any{
+		logAttrRequestID, first.GetId(),
+		logAttrStream, first.GetStream(),
+		logAttrRequestCount, len(reqs),
+		logAttrEntityCount, entityCount(reqs),
+	}, args...)
+}
+
+// clientLogger returns the logger of the client, or a logger discarding all records for other Client implementations
+func clientLogger(client Client) *slog.Logger {
+	if g, ok := client.(*GRPCClient); ok && g.logger != nil {
+		return g.logger
+	}
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newLogger creates a new logger for the SDK
+func newLogger(w io.Writer, format LogFormat) *slog.Logger {
+	level, ok := os.LookupEnv(DiodeSDKLogLevelEnvVarName)
+	if !ok {
+		level = "INFO"
+	}
+
+	var l slog.Level
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		l = slog.LevelDebug
+	case "INFO":
+		l = slog.LevelInfo
+	case "WARN":
+		l = slog.LevelWarn
+	case "ERROR":
+		l = slog.LevelError
+	default:
+		l = slog.LevelDebug
+	}
+
+	if w == nil {
+		w = os.Stderr
+	}
+
+	opts := &slog.HandlerOptions{Level: l, AddSource: false}
+
+	var h slog.Handler
+	if format == LogFormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(h)
+}
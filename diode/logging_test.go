@@ -0,0 +1,155 @@
+package diode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		desc                string
+		logLevelEnvVarValue string
+		wantLogLevel        slog.Level
+	}{
+		{
+			desc:                "log level not provided",
+			logLevelEnvVarValue: "",
+			wantLogLevel:        slog.LevelInfo,
+		},
+		{
+			desc:                "debug log level provided",
+			logLevelEnvVarValue: "debug",
+			wantLogLevel:        slog.LevelDebug,
+		},
+		{
+			desc:                "info log level provided",
+			logLevelEnvVarValue: "info",
+			wantLogLevel:        slog.LevelInfo,
+		},
+		{
+			desc:                "warn log level provided",
+			logLevelEnvVarValue: "warn",
+			wantLogLevel:        slog.LevelWarn,
+		},
+		{
+			desc:                "error log level provided",
+			logLevelEnvVarValue: "error",
+			wantLogLevel:        slog.LevelError,
+		},
+		{
+			desc:                "invalid log level provided",
+			logLevelEnvVarValue: "invalid",
+			wantLogLevel:        slog.LevelDebug,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			defer func() {
+				_ = os.Unsetenv(DiodeSDKLogLevelEnvVarName)
+			}()
+
+			if tt.logLevelEnvVarValue != "" {
+				_ = os.Setenv(DiodeSDKLogLevelEnvVarName, tt.logLevelEnvVarValue)
+			}
+
+			logger := newLogger(io.Discard, LogFormatJSON)
+			require.NotNil(t, logger)
+			assert.True(t, logger.Enabled(context.Background(), tt.wantLogLevel))
+		})
+	}
+}
+
+func TestNewLoggerFormat(t *testing.T) {
+	tests := []struct {
+		desc       string
+		format     LogFormat
+		wantPrefix string
+	}{
+		{
+			desc:       "json format",
+			format:     LogFormatJSON,
+			wantPrefix: "{",
+		},
+		{
+			desc:       "text format",
+			format:     LogFormatText,
+			wantPrefix: "time=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			newLogger(&buf, tt.format).Info("hello", logAttrStream, "latest")
+
+			assert.True(t, strings.HasPrefix(buf.String(), tt.wantPrefix), buf.String())
+			assert.Contains(t, buf.String(), "latest")
+		})
+	}
+}
+
+func TestIngestLogAttributes(t *testing.T) {
+	server := diodetest.Start(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithLogger(logger))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	requestID := "6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11"
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}}, WithRequestID(requestID), WithIngestStream("lab"))
+	require.NoError(t, err)
+
+	var sent map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] == "Ingest request sent" {
+			sent = record
+		}
+	}
+	require.NotNil(t, sent, buf.String())
+	assert.Equal(t, requestID, sent[logAttrRequestID])
+	assert.Equal(t, "lab", sent[logAttrStream])
+	assert.Equal(t, float64(1), sent[logAttrEntityCount])
+}
+
+func TestNewClientLogOutput(t *testing.T) {
+	_ = os.Setenv(DiodeSDKLogLevelEnvVarName, "debug")
+	defer func() {
+		_ = os.Unsetenv(DiodeSDKLogLevelEnvVarName)
+	}()
+
+	server := diodetest.Start(t)
+
+	var buf bytes.Buffer
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithLogOutput(&buf), WithLogFormat(LogFormatText))
+	require.NoError(t, err)
+	require.NoError(t, client.Close())
+
+	assert.Contains(t, buf.String(), "level=DEBUG")
+	assert.Contains(t, buf.String(), logAttrTarget+"=")
+}
+
+func TestClientLogger(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	assert.Same(t, logger, clientLogger(&GRPCClient{logger: logger}))
+	assert.NotNil(t, clientLogger(&MockClient{}))
+}
@@ -0,0 +1,255 @@
+package diode
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// MergePolicy defines how conflicting values of entities with the same identity are merged
+type MergePolicy int
+
+const (
+	// MergeKeepFirst keeps the value of the first entity
+	MergeKeepFirst MergePolicy = iota
+
+	// MergeKeepLast keeps the value of the last entity
+	MergeKeepLast
+
+	// MergeFailOnConflict fails the merge with a MergeConflictError
+	MergeFailOnConflict
+)
+
+// String returns the name of the merge policy
+func (p MergePolicy) String() string {
+	switch p {
+	case MergeKeepFirst:
+		return "keep-first"
+	case MergeKeepLast:
+		return "keep-last"
+	case MergeFailOnConflict:
+		return "fail-on-conflict"
+	default:
+		return fmt.Sprintf("MergePolicy(%d)", int(p))
+	}
+}
+
+// WithDeduplication enables merging the entities with the same identity of each Ingest call into one entity, after
+// normalizing and before validating them, entities are not deduplicated by default
+//
+// Ingest fails with a MergeConflictError on conflicting values with MergeFailOnConflict. See Deduplicate.
+func WithDeduplication(policy MergePolicy) ClientOption {
+	return func(c *GRPCClient) {
+		c.deduplicate = true
+		c.mergePolicy = policy
+	}
+}
+
+// MergeConflictError is a field set to different values in entities with the same identity
+type MergeConflictError struct {
+	// Identity is the identity of the merged entities
+	Identity Identity
+
+	// Field is the path of the conflicting field within the entity, e.g. device.serial
+	Field string
+}
+
+// Error returns the conflicting field and the identity of the entities
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("conflicting values of %s in entities %s", e.Field, e.Identity)
+}
+
+// Merge merges two partial entities with the same identity into a new entity, see IdentityOf
+//
+// Fields set in only one of the entities are kept, the elements of repeated fields are combined, nested objects with
+// the same identity are merged recursively, and fields set to different values are resolved by the policy. The later
+// discovery timestamp of TimestampedEntity is kept.
+func Merge(a Entity, b Entity, policy MergePolicy) (Entity, error) {
+	dst := proto.Clone(a.ConvertToProtoEntity()).(*diodepb.Entity)
+	m, err := mergeEntities(dst, b.ConvertToProtoEntity(), policy)
+	if err != nil {
+		return nil, err
+	}
+	return EntityFromProto(m)
+}
+
+// Deduplicate merges the entities with the same identity into one entity at the position of the first of them, see
+// Merge
+func Deduplicate(entities []Entity, policy MergePolicy) ([]Entity, error) {
+	protoEntities := make([]*diodepb.Entity, 0, len(entities))
+	for _, entity := range entities {
+		protoEntities = append(protoEntities, entity.ConvertToProtoEntity())
+	}
+
+	merged, _, err := deduplicateProtoEntities(protoEntities, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	deduplicated := make([]Entity, 0, len(merged))
+	for _, m := range merged {
+		entity, err := EntityFromProto(m)
+		if err != nil {
+			return nil, err
+		}
+		deduplicated = append(deduplicated, entity)
+	}
+	return deduplicated, nil
+}
+
+// deduplicateProtoEntities merges the entities with the same identity into a copy of the first of them, entities
+// without type are kept as is
+//
+// The indexes of the first entities in the given entities are returned along with the deduplicated entities.
+func deduplicateProtoEntities(entities []*diodepb.Entity, policy MergePolicy) ([]*diodepb.Entity, []int, error) {
+	type first struct {
+		index  int
+		cloned bool
+	}
+
+	deduplicated := make([]*diodepb.Entity, 0, len(entities))
+	indexes := make([]int, 0, len(entities))
+	seen := make(map[Identity]*first)
+	for i, entity := range entities {
+		id, ok := entityIdentity(entity)
+		if !ok {
+			deduplicated = append(deduplicated, entity)
+			indexes = append(indexes, i)
+			continue
+		}
+
+		f, ok := seen[id]
+		if !ok {
+			seen[id] = &first{index: len(deduplicated)}
+			deduplicated = append(deduplicated, entity)
+			indexes = append(indexes, i)
+			continue
+		}
+
+		if !f.cloned {
+			deduplicated[f.index] = proto.Clone(deduplicated[f.index]).(*diodepb.Entity)
+			f.cloned = true
+		}
+		if _, err := mergeEntities(deduplicated[f.index], entity, policy); err != nil {
+			return nil, nil, err
+		}
+	}
+	return deduplicated, indexes, nil
+}
+
+// mergeEntities merges src into dst and returns dst
+//
+// Optional fields of converted entities share their pointers with the entities, dst must be a copy so that merging
+// does not modify them. src is copied before its values are moved to dst.
+func mergeEntities(dst *diodepb.Entity, src *diodepb.Entity, policy MergePolicy) (*diodepb.Entity, error) {
+	dstID, dstOK := entityIdentity(dst)
+	srcID, srcOK := entityIdentity(src)
+	if !dstOK || !srcOK {
+		return nil, errors.New("entity has no type set")
+	}
+	if dstID != srcID {
+		return nil, fmt.Errorf("cannot merge entities with different identities %s and %s", dstID, srcID)
+	}
+
+	src = proto.Clone(src).(*diodepb.Entity)
+	fd := dst.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(dstID.Type))
+	m := merger{policy: policy}
+	m.message(dst.ProtoReflect().Get(fd).Message(), src.ProtoReflect().Get(fd).Message(), dstID.Type)
+	if m.conflict != "" {
+		return nil, &MergeConflictError{Identity: dstID, Field: m.conflict}
+	}
+
+	if src.GetTimestamp() != nil && (dst.GetTimestamp() == nil || src.GetTimestamp().AsTime().After(dst.GetTimestamp().AsTime())) {
+		dst.Timestamp = src.GetTimestamp()
+	}
+	return dst, nil
+}
+
+// merger merges messages according to a policy
+type merger struct {
+	policy MergePolicy
+
+	// conflict is the path of the first conflicting field with MergeFailOnConflict
+	conflict string
+}
+
+// message merges the fields of src into dst, path is the path of the messages within the entity
+func (m *merger) message(dst protoreflect.Message, src protoreflect.Message, path string) {
+	src.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		field := path + "." + string(fd.Name())
+
+		switch {
+		case !dst.Has(fd):
+			if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() && dst.WhichOneof(od) != nil {
+				m.resolve(dst, fd, v, path+"."+string(od.Name()))
+				break
+			}
+			dst.Set(fd, v)
+		case fd.IsList():
+			m.list(dst.Mutable(fd).List(), v.List(), field)
+		case fd.Message() != nil:
+			d := dst.Mutable(fd).Message()
+			switch {
+			case hasIdentity(d) && identityKey(d) == identityKey(v.Message()):
+				m.message(d, v.Message(), field)
+			case !proto.Equal(d.Interface(), v.Message().Interface()):
+				m.resolve(dst, fd, v, field)
+			}
+		case !dst.Get(fd).Equal(v):
+			m.resolve(dst, fd, v, field)
+		}
+
+		return m.conflict == ""
+	})
+}
+
+// list appends the elements of src missing in dst, elements with the same identity are merged
+func (m *merger) list(dst protoreflect.List, src protoreflect.List, path string) {
+	for i := 0; i < src.Len() && m.conflict == ""; i++ {
+		v := src.Get(i)
+		if j := indexOf(dst, v); j >= 0 {
+			if isMessage(v) {
+				m.message(dst.Get(j).Message(), v.Message(), path)
+			}
+			continue
+		}
+		dst.Append(v)
+	}
+}
+
+// resolve resolves a conflicting value of a field according to the policy
+func (m *merger) resolve(dst protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) {
+	switch m.policy {
+	case MergeKeepLast:
+		dst.Set(fd, v)
+	case MergeFailOnConflict:
+		m.conflict = path
+	}
+}
+
+// indexOf returns the index of the element of a list equal to or with the same identity as v, or -1
+func indexOf(l protoreflect.List, v protoreflect.Value) int {
+	for i := 0; i < l.Len(); i++ {
+		e := l.Get(i)
+		if isMessage(v) && hasIdentity(v.Message()) {
+			if identityKey(e.Message()) == identityKey(v.Message()) {
+				return i
+			}
+			continue
+		}
+		if e.Equal(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isMessage reports whether a value holds a message
+func isMessage(v protoreflect.Value) bool {
+	_, ok := v.Interface().(protoreflect.Message)
+	return ok
+}
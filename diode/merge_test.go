@@ -0,0 +1,274 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+func TestMerge(t *testing.T) {
+	earlier := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	tests := []struct {
+		desc    string
+		a       Entity
+		b       Entity
+		policy  MergePolicy
+		want    Entity
+		wantErr string
+	}{
+		{
+			desc:   "partial fields combined",
+			a:      &Device{Name: String("sw1"), Serial: String("SN1")},
+			b:      &Device{Name: String("sw1"), AssetTag: String("A1"), Status: String("active")},
+			policy: MergeFailOnConflict,
+			want:   &Device{Name: String("sw1"), Serial: String("SN1"), AssetTag: String("A1"), Status: String("active")},
+		},
+		{
+			desc: "nested objects with the same identity merged",
+			a: &Device{
+				Name: String("sw1"),
+				Site: &Site{Name: String("dc1")},
+				Role: &Role{Name: String("leaf")},
+			},
+			b: &Device{
+				Name:     String("sw1"),
+				Site:     &Site{Name: String("dc1"), Slug: String("dc1")},
+				Platform: &Platform{Name: String("eos")},
+			},
+			policy: MergeFailOnConflict,
+			want: &Device{
+				Name:     String("sw1"),
+				Site:     &Site{Name: String("dc1"), Slug: String("dc1")},
+				Role:     &Role{Name: String("leaf")},
+				Platform: &Platform{Name: String("eos")},
+			},
+		},
+		{
+			desc:   "tags combined",
+			a:      &Site{Name: String("dc1"), Tags: []*Tag{{Name: String("prod")}, {Name: String("edge")}}},
+			b:      &Site{Name: String("dc1"), Tags: []*Tag{{Name: String("prod"), Slug: String("prod")}, {Name: String("core")}}},
+			policy: MergeFailOnConflict,
+			want: &Site{Name: String("dc1"), Tags: []*Tag{
+				{Name: String("prod"), Slug: String("prod")},
+				{Name: String("edge")},
+				{Name: String("core")},
+			}},
+		},
+		{
+			desc:   "conflict keeps first",
+			a:      &Device{Name: String("sw1"), Serial: String("SN1")},
+			b:      &Device{Name: String("sw1"), Serial: String("SN2")},
+			policy: MergeKeepFirst,
+			want:   &Device{Name: String("sw1"), Serial: String("SN1")},
+		},
+		{
+			desc:   "conflict keeps last",
+			a:      &Device{Name: String("sw1"), Serial: String("SN1"), Role: &Role{Name: String("leaf")}},
+			b:      &Device{Name: String("sw1"), Serial: String("SN2"), Role: &Role{Name: String("spine")}},
+			policy: MergeKeepLast,
+			want:   &Device{Name: String("sw1"), Serial: String("SN2"), Role: &Role{Name: String("spine")}},
+		},
+		{
+			desc:    "conflict fails",
+			a:       &Device{Name: String("sw1"), Site: &Site{Name: String("dc1"), Status: String("active")}},
+			b:       &Device{Name: String("sw1"), Site: &Site{Name: String("dc1"), Status: String("planned")}},
+			policy:  MergeFailOnConflict,
+			wantErr: `conflicting values of device.site.status in entities device:name="sw1",site=(name="dc1")`,
+		},
+		{
+			desc:    "nested objects with different identities conflict",
+			a:       &Device{Name: String("sw1"), Role: &Role{Name: String("leaf")}},
+			b:       &Device{Name: String("sw1"), Role: &Role{Name: String("spine")}},
+			policy:  MergeFailOnConflict,
+			wantErr: `conflicting values of device.role in entities device:name="sw1",site=`,
+		},
+		{
+			desc:   "later timestamp kept",
+			a:      Timestamped(&Site{Name: String("dc1")}, later),
+			b:      Timestamped(&Site{Name: String("dc1"), Slug: String("dc1")}, earlier),
+			policy: MergeFailOnConflict,
+			want:   Timestamped(&Site{Name: String("dc1"), Slug: String("dc1")}, later),
+		},
+		{
+			desc:   "timestamp set",
+			a:      &Site{Name: String("dc1")},
+			b:      Timestamped(&Site{Name: String("dc1")}, earlier),
+			policy: MergeFailOnConflict,
+			want:   Timestamped(&Site{Name: String("dc1")}, earlier),
+		},
+		{
+			desc:    "different identities",
+			a:       &Site{Name: String("dc1")},
+			b:       &Site{Name: String("dc2")},
+			policy:  MergeKeepLast,
+			wantErr: `cannot merge entities with different identities site:name="dc1" and site:name="dc2"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Merge(tt.a, tt.b, tt.policy)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(tt.want.ConvertToProtoEntity(), got.ConvertToProtoEntity()), "got %v", got.ConvertToProtoEntity())
+		})
+	}
+}
+
+func TestMergeDoesNotModifyEntities(t *testing.T) {
+	a := &Device{Name: String("sw1"), Serial: String("SN1"), Tags: []*Tag{{Name: String("prod")}}}
+	b := &Device{Name: String("sw1"), Serial: String("SN2"), Tags: []*Tag{{Name: String("edge")}}}
+
+	_, err := Merge(a, b, MergeKeepLast)
+	require.NoError(t, err)
+
+	assert.Equal(t, "SN1", *a.Serial)
+	assert.Len(t, a.Tags, 1)
+	assert.Equal(t, "SN2", *b.Serial)
+
+	c := &Device{Name: String("sw1"), Serial: String("SN3")}
+	_, err = Deduplicate([]Entity{a, b, c}, MergeKeepLast)
+	require.NoError(t, err)
+
+	assert.Equal(t, "SN1", *a.Serial)
+	assert.Equal(t, "SN2", *b.Serial)
+	assert.Equal(t, "SN3", *c.Serial)
+}
+
+func TestDeduplicate(t *testing.T) {
+	entities := []Entity{
+		&Device{Name: String("sw1"), Serial: String("SN1")},
+		&Interface{Name: String("eth0"), Device: &Device{Name: String("sw1")}},
+		&Device{Name: String("sw1"), AssetTag: String("A1")},
+		&Device{Name: String("sw1"), Site: &Site{Name: String("dc1")}},
+		&Interface{Name: String("eth0"), Device: &Device{Name: String("sw1")}, Mtu: Int32(9000)},
+	}
+
+	got, err := Deduplicate(entities, MergeFailOnConflict)
+	require.NoError(t, err)
+
+	requireEqualEntityLists(t, EntityList{
+		&Device{Name: String("sw1"), Serial: String("SN1"), AssetTag: String("A1")},
+		&Interface{Name: String("eth0"), Device: &Device{Name: String("sw1")}, Mtu: Int32(9000)},
+		&Device{Name: String("sw1"), Site: &Site{Name: String("dc1")}},
+	}, got)
+}
+
+func TestIngestDeduplication(t *testing.T) {
+	tests := []struct {
+		desc         string
+		clientOpts   []ClientOption
+		wantEntities int
+		wantErr      bool
+	}{
+		{
+			desc:         "disabled by default",
+			wantEntities: 3,
+		},
+		{
+			desc:         "keep first",
+			clientOpts:   []ClientOption{WithDeduplication(MergeKeepFirst)},
+			wantEntities: 2,
+		},
+		{
+			desc:       "fail on conflict",
+			clientOpts: []ClientOption{WithDeduplication(MergeFailOnConflict)},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			opts := append([]ClientOption{WithAPIKey("abcde")}, tt.clientOpts...)
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", opts...)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			_, err = client.Ingest(context.Background(), []Entity{
+				&Device{Name: String("sw1"), Serial: String("SN1")},
+				&Device{Name: String("sw1"), Serial: String("SN2")},
+				&Device{Name: String("SW1 ")},
+			})
+			if tt.wantErr {
+				var conflict *MergeConflictError
+				require.True(t, errors.As(err, &conflict))
+				assert.Equal(t, "device.serial", conflict.Field)
+				assert.Empty(t, server.Requests())
+				return
+			}
+			require.NoError(t, err)
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			assert.Len(t, requests[0].GetEntities(), tt.wantEntities)
+		})
+	}
+}
+
+func TestIngestDeduplicationValidationIndexes(t *testing.T) {
+	entities := []Entity{
+		&Site{Name: String("dc1"), Slug: String("dc1"), Status: String("active")},
+		&Site{Name: String("dc1"), Slug: String("dc1")},
+		&Site{Name: String("dc2"), Slug: String("dc2"), Status: String("unknown")},
+	}
+
+	tests := []struct {
+		desc string
+		mode ValidationMode
+	}{
+		{desc: "fail fast", mode: ValidationFailFast},
+		{desc: "skip invalid", mode: ValidationSkipInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"),
+				WithDeduplication(MergeKeepFirst), WithValidation(tt.mode))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			resp, err := client.Ingest(context.Background(), entities)
+			if tt.mode == ValidationFailFast {
+				var errs ValidationErrors
+				require.True(t, errors.As(err, &errs))
+				assert.Equal(t, []int{2}, errs.Indexes())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resp.GetErrors(), 1)
+			assert.True(t, strings.HasPrefix(resp.GetErrors()[0], "entity 2: "), resp.GetErrors()[0])
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			assert.Len(t, requests[0].GetEntities(), 1)
+		})
+	}
+}
+
+func TestMergePolicyString(t *testing.T) {
+	assert.Equal(t, "keep-first", MergeKeepFirst.String())
+	assert.Equal(t, "keep-last", MergeKeepLast.String())
+	assert.Equal(t, "fail-on-conflict", MergeFailOnConflict.String())
+	assert.Equal(t, "MergePolicy(42)", MergePolicy(42).String())
+}
@@ -0,0 +1,179 @@
+package diode
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// Normalizer rewrites fields of entity messages into a canonical form before they are validated and sent, so that
+// values reported differently by collectors match the same NetBox objects
+type Normalizer interface {
+	// Normalize rewrites the message in place, it is called for every entity message and every message nested in it
+	//
+	// Optional fields share their pointers with the ingested entities, normalizers set new pointers rather than
+	// writing through them so that the entities are not modified.
+	Normalize(m proto.Message)
+}
+
+// NormalizerFunc is a function implementing Normalizer
+type NormalizerFunc func(m proto.Message)
+
+// Normalize calls the function
+func (f NormalizerFunc) Normalize(m proto.Message) {
+	f(m)
+}
+
+// WithNormalizers adds normalizers applied in order to the entities before each Ingest call, entities are not
+// normalized by default
+//
+// Use DefaultNormalizers for the built-in network value normalizers, or pick some of them and add custom ones.
+func WithNormalizers(normalizers ...Normalizer) ClientOption {
+	return func(c *GRPCClient) {
+		c.normalizers = append(c.normalizers, normalizers...)
+	}
+}
+
+// DefaultNormalizers returns the built-in network value normalizers
+func DefaultNormalizers() []Normalizer {
+	return []Normalizer{
+		MACAddressNormalizer(),
+		IPAddressNormalizer(),
+		PrefixNormalizer(),
+		DNSNameNormalizer(),
+	}
+}
+
+// MACAddressNormalizer returns a normalizer rewriting the MAC addresses of interfaces and virtual machine interfaces
+// as uppercase colon separated EUI-48 addresses, e.g. aabb.ccdd.eeff and AA-BB-CC-DD-EE-FF become AA:BB:CC:DD:EE:FF
+func MACAddressNormalizer() Normalizer {
+	return NormalizerFunc(func(m proto.Message) {
+		switch m := m.(type) {
+		case *diodepb.Interface:
+			m.MacAddress = normalizeOptional(m.MacAddress, canonicalMACAddress)
+		case *diodepb.VMInterface:
+			m.MacAddress = normalizeOptional(m.MacAddress, canonicalMACAddress)
+		}
+	})
+}
+
+// IPAddressNormalizer returns a normalizer rewriting the addresses of IP addresses in canonical form without adding or
+// removing a prefix length, e.g. 2001:DB8:0::1 becomes 2001:db8::1 and 2001:DB8:0::1/64 becomes 2001:db8::1/64
+func IPAddressNormalizer() Normalizer {
+	return NormalizerFunc(func(m proto.Message) {
+		if ip, ok := m.(*diodepb.IPAddress); ok {
+			ip.Address = canonicalIPAddress(ip.Address)
+		}
+	})
+}
+
+// PrefixNormalizer returns a normalizer rewriting prefixes in canonical form without adding or removing a prefix length,
+// clearing the host bits of prefixes with a length, e.g. 2001:DB8:: becomes 2001:db8:: and 192.168.0.1/24 becomes
+// 192.168.0.0/24
+func PrefixNormalizer() Normalizer {
+	return NormalizerFunc(func(m proto.Message) {
+		if p, ok := m.(*diodepb.Prefix); ok {
+			p.Prefix = canonicalPrefix(p.Prefix)
+		}
+	})
+}
+
+// DNSNameNormalizer returns a normalizer rewriting the FQDNs of devices and the DNS names of IP addresses in lowercase
+// without trailing dot, e.g. SW1.Example.COM. becomes sw1.example.com
+func DNSNameNormalizer() Normalizer {
+	return NormalizerFunc(func(m proto.Message) {
+		switch m := m.(type) {
+		case *diodepb.Device:
+			m.DeviceFqdn = normalizeOptional(m.DeviceFqdn, canonicalDNSName)
+		case *diodepb.IPAddress:
+			m.DnsName = normalizeOptional(m.DnsName, canonicalDNSName)
+		}
+	})
+}
+
+// normalize applies the normalizers to a message and all messages nested in it
+func normalize(m protoreflect.Message, normalizers []Normalizer) {
+	for _, n := range normalizers {
+		n.Normalize(m.Interface())
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil || fd.IsMap():
+		case fd.IsList():
+			for i := 0; i < v.List().Len(); i++ {
+				normalize(v.List().Get(i).Message(), normalizers)
+			}
+		default:
+			normalize(v.Message(), normalizers)
+		}
+		return true
+	})
+}
+
+// normalizeOptional returns a new pointer to the canonical form of an optional value, or the value if it is unset or
+// already canonical
+func normalizeOptional(s *string, canonical func(string) string) *string {
+	if s == nil {
+		return nil
+	}
+	if c := canonical(*s); c != *s {
+		return &c
+	}
+	return s
+}
+
+// canonicalMACAddress returns the uppercase colon separated form of an EUI-48 address, or the value if it is not one
+func canonicalMACAddress(s string) string {
+	hw, err := net.ParseMAC(strings.TrimSpace(s))
+	if err != nil || len(hw) != 6 {
+		return s
+	}
+	return strings.ToUpper(hw.String())
+}
+
+// canonicalIPAddress returns the canonical form of an address in the notation it is given in, with or without prefix
+// length, or the value if it is not an address
+func canonicalIPAddress(s string) string {
+	if a, ok := parseAddr(s); ok {
+		return a.String()
+	}
+	if p, err := netip.ParsePrefix(strings.TrimSpace(s)); err == nil {
+		return p.String()
+	}
+	return s
+}
+
+// canonicalPrefix returns the canonical form of a prefix in the notation it is given in, with the host bits cleared if
+// it has a prefix length, or the value if it is not a prefix
+func canonicalPrefix(s string) string {
+	if a, ok := parseAddr(s); ok {
+		return a.String()
+	}
+	if p, err := netip.ParsePrefix(strings.TrimSpace(s)); err == nil {
+		return p.Masked().String()
+	}
+	return s
+}
+
+// parseAddr parses an address without prefix length and zone
+func parseAddr(s string) (netip.Addr, bool) {
+	a, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil || a.Zone() != "" {
+		return netip.Addr{}, false
+	}
+	return a, true
+}
+
+// canonicalDNSName returns the lowercase form of a DNS name without trailing dot, or the value if it is empty
+func canonicalDNSName(s string) string {
+	if c := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), ".")); c != "" {
+		return c
+	}
+	return s
+}
@@ -0,0 +1,201 @@
+package diode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestNormalizers(t *testing.T) {
+	tests := []struct {
+		desc       string
+		normalizer Normalizer
+		entity     Entity
+		want       proto.Message
+	}{
+		{
+			desc:       "dotted MAC address",
+			normalizer: MACAddressNormalizer(),
+			entity:     &Interface{Name: String("eth0"), MacAddress: String("aabb.ccdd.eeff")},
+			want:       &diodepb.Interface{Name: "eth0", MacAddress: String("AA:BB:CC:DD:EE:FF")},
+		},
+		{
+			desc:       "hyphenated VM interface MAC address",
+			normalizer: MACAddressNormalizer(),
+			entity:     &VMInterface{Name: String("eth0"), MacAddress: String(" aa-bb-cc-dd-ee-ff ")},
+			want:       &diodepb.VMInterface{Name: "eth0", MacAddress: String("AA:BB:CC:DD:EE:FF")},
+		},
+		{
+			desc:       "invalid MAC address unchanged",
+			normalizer: MACAddressNormalizer(),
+			entity:     &Interface{Name: String("eth0"), MacAddress: String("not-a-mac")},
+			want:       &diodepb.Interface{Name: "eth0", MacAddress: String("not-a-mac")},
+		},
+		{
+			desc:       "EUI-64 address unchanged",
+			normalizer: MACAddressNormalizer(),
+			entity:     &Interface{Name: String("eth0"), MacAddress: String("02:00:5e:10:00:00:00:01")},
+			want:       &diodepb.Interface{Name: "eth0", MacAddress: String("02:00:5e:10:00:00:00:01")},
+		},
+		{
+			desc:       "IPv4 address without prefix length",
+			normalizer: IPAddressNormalizer(),
+			entity:     &IPAddress{Address: String(" 192.168.0.1 ")},
+			want:       &diodepb.IPAddress{Address: "192.168.0.1"},
+		},
+		{
+			desc:       "IPv6 address",
+			normalizer: IPAddressNormalizer(),
+			entity:     &IPAddress{Address: String("2001:DB8:0::1/64")},
+			want:       &diodepb.IPAddress{Address: "2001:db8::1/64"},
+		},
+		{
+			desc:       "IPv6 address without prefix length",
+			normalizer: IPAddressNormalizer(),
+			entity:     &IPAddress{Address: String("2001:DB8:0::1")},
+			want:       &diodepb.IPAddress{Address: "2001:db8::1"},
+		},
+		{
+			desc:       "IP address host bits kept",
+			normalizer: IPAddressNormalizer(),
+			entity:     &IPAddress{Address: String("10.0.0.5/24")},
+			want:       &diodepb.IPAddress{Address: "10.0.0.5/24"},
+		},
+		{
+			desc:       "invalid IP address unchanged",
+			normalizer: IPAddressNormalizer(),
+			entity:     &IPAddress{Address: String("fe80::1%eth0")},
+			want:       &diodepb.IPAddress{Address: "fe80::1%eth0"},
+		},
+		{
+			desc:       "prefix host bits cleared",
+			normalizer: PrefixNormalizer(),
+			entity:     &Prefix{Prefix: String("192.168.0.1/24")},
+			want:       &diodepb.Prefix{Prefix: "192.168.0.0/24"},
+		},
+		{
+			desc:       "prefix without length",
+			normalizer: PrefixNormalizer(),
+			entity:     &Prefix{Prefix: String("2001:DB8::")},
+			want:       &diodepb.Prefix{Prefix: "2001:db8::"},
+		},
+		{
+			desc:       "device FQDN",
+			normalizer: DNSNameNormalizer(),
+			entity:     &Device{Name: String("sw1"), DeviceFqdn: String("SW1.Example.COM.")},
+			want:       &diodepb.Device{Name: "sw1", DeviceFqdn: String("sw1.example.com")},
+		},
+		{
+			desc:       "IP address DNS name",
+			normalizer: DNSNameNormalizer(),
+			entity:     &IPAddress{Address: String("10.0.0.1/24"), DnsName: String("Host1.Example.com")},
+			want:       &diodepb.IPAddress{Address: "10.0.0.1/24", DnsName: String("host1.example.com")},
+		},
+		{
+			desc:       "other messages unchanged",
+			normalizer: DNSNameNormalizer(),
+			entity:     &Site{Name: String("DC1")},
+			want:       &diodepb.Site{Name: "DC1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m := tt.entity.ConvertToProtoMessage()
+			tt.normalizer.Normalize(m)
+			assert.True(t, proto.Equal(tt.want, m), "got %v", m)
+		})
+	}
+}
+
+func TestNormalizeNestedMessages(t *testing.T) {
+	iface := &Interface{
+		Name:       String("eth0"),
+		MacAddress: String("aa-bb-cc-dd-ee-ff"),
+		Device:     &Device{Name: String("sw1"), PrimaryIp4: &IPAddress{Address: String("10.0.0.1")}},
+	}
+	ip := &IPAddress{Address: String("10.0.0.2"), AssignedObject: iface}
+
+	m := ip.ConvertToProtoEntity()
+	normalize(m.ProtoReflect(), DefaultNormalizers())
+
+	got := m.GetIpAddress()
+	assert.Equal(t, "10.0.0.2", got.GetAddress())
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", got.GetInterface().GetMacAddress())
+	assert.Equal(t, "10.0.0.1", got.GetInterface().GetDevice().GetPrimaryIp4().GetAddress())
+
+	assert.Equal(t, "aa-bb-cc-dd-ee-ff", *iface.MacAddress, "entity must not be modified")
+}
+
+func TestIngestNormalizers(t *testing.T) {
+	var calls []string
+	custom := NormalizerFunc(func(m proto.Message) {
+		if site, ok := m.(*diodepb.Site); ok {
+			calls = append(calls, site.GetName())
+			site.Name += "-normalized"
+		}
+	})
+
+	server := diodetest.Start(t)
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0",
+		WithAPIKey("abcde"),
+		WithDefaultTimestamp(false),
+		WithNormalizers(MACAddressNormalizer(), custom),
+		WithSlugGeneration(),
+	)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []Entity{
+		&Device{Name: String("sw1"), Site: &Site{Name: String("dc1")}},
+		&Interface{Name: String("eth0"), MacAddress: String("aabb.ccdd.eeff")},
+	})
+	require.NoError(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	entities := requests[0].GetEntities()
+	require.Len(t, entities, 2)
+	assert.Equal(t, []string{"dc1"}, calls)
+	assert.Equal(t, "dc1-normalized", entities[0].GetDevice().GetSite().GetName())
+	assert.Equal(t, "dc1-normalized", entities[0].GetDevice().GetSite().GetSlug(), "normalizers must run in order")
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", entities[1].GetInterface().GetMacAddress())
+}
+
+func TestIngestDefaultNormalizersWithValidation(t *testing.T) {
+	server := diodetest.Start(t)
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0",
+		WithAPIKey("abcde"),
+		WithNormalizers(DefaultNormalizers()...),
+		WithValidation(ValidationFailFast),
+	)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []Entity{
+		&IPAddress{Address: String("192.168.0.1"), Status: String("active"), Role: String("vip")},
+		&IPAddress{Address: String("2001:DB8:0::1"), Status: String("active"), Role: String("vip")},
+		&Prefix{Prefix: String("10.0.0.0"), Status: String("active")},
+	})
+	require.NoError(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	entities := requests[0].GetEntities()
+	require.Len(t, entities, 3)
+	assert.Equal(t, "192.168.0.1", entities[0].GetIpAddress().GetAddress())
+	assert.Equal(t, "2001:db8::1", entities[1].GetIpAddress().GetAddress())
+	assert.Equal(t, "10.0.0.0", entities[2].GetPrefix().GetPrefix())
+}
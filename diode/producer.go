@@ -0,0 +1,354 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+const (
+	// DefaultProducerLinger is the default maximum time an entity waits in the producer before its batch is sent
+	DefaultProducerLinger = time.Second
+
+	// DefaultProducerBufferSize is the default number of entities buffered by the producer before Add blocks
+	DefaultProducerBufferSize = 10000
+)
+
+// ErrProducerClosed is returned when adding entities to or flushing a closed producer
+var ErrProducerClosed = errors.New("producer is closed")
+
+// BatchOutcome is the outcome of a batch sent by the producer
+type BatchOutcome struct {
+	// RequestID is the ID of the ingest request the batch was sent with
+	RequestID string
+
+	// Entities are the entities of the batch
+	Entities []Entity
+
+	// Response is the ingester service response, nil if the request failed
+	Response *diodepb.IngestResponse
+
+	// Err is the error returned by the client, nil if the request succeeded
+	Err error
+}
+
+// Producer buffers entities and sends them in batches in the background
+//
+// A batch is sent when it reaches the maximum number of entities or marshalled size, when its oldest entity has
+// waited for the linger time, or when the producer is flushed or closed.
+type Producer struct {
+	// The client used to send the batches
+	client Client
+
+	// Maximum number of entities in a batch
+	maxBatchSize int
+
+	// Maximum marshalled size of the entities in a batch
+	maxBatchBytes int
+
+	// Maximum time an entity waits before its batch is sent
+	linger time.Duration
+
+	// Number of entities buffered before Add blocks
+	bufferSize int
+
+	// Callback receiving the outcome of every batch
+	callback func(BatchOutcome)
+
+	// Options applied to every ingest request
+	ingestOpts []IngestOption
+
+	// The logger for the producer
+	logger *slog.Logger
+
+	// Context of the ingest requests, canceled when closing times out
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Guards closed against concurrent Add calls
+	mu     sync.RWMutex
+	closed bool
+
+	// Closed when the producer starts closing, unblocks pending Add calls
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// Buffered entities
+	in chan Entity
+
+	// Flush requests handled by the background loop
+	flushes chan flushRequest
+
+	// Closed when the background loop has stopped
+	done chan struct{}
+}
+
+// flushRequest asks the background loop to send all buffered entities
+type flushRequest struct {
+	// Closed once the buffered entities have been sent
+	done chan struct{}
+
+	// Whether the loop stops after flushing
+	stop bool
+}
+
+// ProducerOption is a functional option for the Producer
+type ProducerOption func(*Producer)
+
+// WithProducerMaxBatchSize sets the maximum number of entities in a batch, DefaultBatchMaxEntities by default
+//
+// DefaultBatchMaxEntities is the limit enforced by the ingester service, larger values are capped to it with a warning
+// and values below 1 select it.
+func WithProducerMaxBatchSize(n int) ProducerOption {
+	return func(p *Producer) {
+		p.maxBatchSize = n
+	}
+}
+
+// WithProducerMaxBatchBytes sets the maximum marshalled size of the entities in a batch
+func WithProducerMaxBatchBytes(n int) ProducerOption {
+	return func(p *Producer) {
+		p.maxBatchBytes = n
+	}
+}
+
+// WithProducerLinger sets the maximum time an entity waits before its batch is sent
+func WithProducerLinger(d time.Duration) ProducerOption {
+	return func(p *Producer) {
+		p.linger = d
+	}
+}
+
+// WithProducerBufferSize sets the number of entities buffered before Add blocks
+func WithProducerBufferSize(n int) ProducerOption {
+	return func(p *Producer) {
+		p.bufferSize = n
+	}
+}
+
+// WithProducerCallback sets the callback receiving the outcome of every batch
+//
+// The callback is called from the producer's background goroutine and must not call Add, Flush or Close.
+func WithProducerCallback(fn func(BatchOutcome)) ProducerOption {
+	return func(p *Producer) {
+		p.callback = fn
+	}
+}
+
+// WithProducerIngestOptions sets the options applied to every ingest request sent by the producer
+func WithProducerIngestOptions(opts ...IngestOption) ProducerOption {
+	return func(p *Producer) {
+		p.ingestOpts = opts
+	}
+}
+
+// NewProducer creates a new producer sending batches with the given client and starts its background goroutine
+func NewProducer(client Client, opts ...ProducerOption) *Producer {
+	p := &Producer{
+		client:        client,
+		maxBatchSize:  DefaultBatchMaxEntities,
+		maxBatchBytes: DefaultBatchMaxBytes,
+		linger:        DefaultProducerLinger,
+		bufferSize:    DefaultProducerBufferSize,
+		logger:        clientLogger(client),
+		closing:       make(chan struct{}),
+		flushes:       make(chan flushRequest),
+		done:          make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(p)
+	}
+
+	if p.maxBatchSize > DefaultBatchMaxEntities {
+		p.logger.Warn("Producer max batch size capped to the ingester service limit", logAttrMaxEntities, p.maxBatchSize,
+			logAttrLimit, DefaultBatchMaxEntities)
+	}
+	if p.maxBatchSize < 1 || p.maxBatchSize > DefaultBatchMaxEntities {
+		p.maxBatchSize = DefaultBatchMaxEntities
+	}
+	if p.maxBatchBytes < 1 {
+		p.maxBatchBytes = DefaultBatchMaxBytes
+	}
+	if p.linger <= 0 {
+		p.linger = DefaultProducerLinger
+	}
+	if p.bufferSize < 0 {
+		p.bufferSize = DefaultProducerBufferSize
+	}
+
+	p.in = make(chan Entity, p.bufferSize)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	go p.run()
+
+	return p
+}
+
+// Add adds an entity to the producer, blocking while the buffer is full
+func (p *Producer) Add(entity Entity) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrProducerClosed
+	}
+
+	select {
+	case p.in <- entity:
+		return nil
+	case <-p.closing:
+		return ErrProducerClosed
+	}
+}
+
+// Flush sends all entities added so far and waits until their batches have been sent
+func (p *Producer) Flush(ctx context.Context) error {
+	return p.flush(ctx, false)
+}
+
+// Close sends all buffered entities and stops the producer
+//
+// If the context is done before the buffered entities have been sent, pending ingest requests are canceled.
+func (p *Producer) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+
+		// wait for in-flight Add calls, later ones see the producer as closed
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+	})
+
+	err := p.flush(ctx, true)
+	if err != nil && !errors.Is(err, ErrProducerClosed) {
+		// cancel pending ingest requests so that the remaining batches fail fast and the loop stops
+		p.cancel()
+		_ = p.flush(context.Background(), true)
+		<-p.done
+		return err
+	}
+
+	p.cancel()
+	return nil
+}
+
+// flush sends a flush request to the background loop and waits for it to complete
+func (p *Producer) flush(ctx context.Context, stop bool) error {
+	req := flushRequest{done: make(chan struct{}), stop: stop}
+
+	select {
+	case p.flushes <- req:
+	case <-p.done:
+		return ErrProducerClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-req.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run is the background loop batching buffered entities
+func (p *Producer) run() {
+	defer close(p.done)
+
+	var batch []Entity
+	var batchBytes int
+
+	var timer *time.Timer
+	var lingerC <-chan time.Time
+
+	send := func() {
+		if timer != nil {
+			timer.Stop()
+			timer = nil
+			lingerC = nil
+		}
+		if len(batch) == 0 {
+			return
+		}
+		p.send(batch)
+		batch = nil
+		batchBytes = 0
+	}
+
+	add := func(entity Entity) {
+		size := entityWireSize(entity)
+		if len(batch) > 0 && batchBytes+size > p.maxBatchBytes {
+			send()
+		}
+
+		batch = append(batch, entity)
+		batchBytes += size
+
+		if len(batch) >= p.maxBatchSize {
+			send()
+			return
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(p.linger)
+			lingerC = timer.C
+		}
+	}
+
+	for {
+		select {
+		case entity := <-p.in:
+			add(entity)
+		case <-lingerC:
+			timer = nil
+			lingerC = nil
+			send()
+		case req := <-p.flushes:
+			p.drain(add)
+			send()
+			close(req.done)
+			if req.stop {
+				return
+			}
+		}
+	}
+}
+
+// drain passes all currently buffered entities to add
+func (p *Producer) drain(add func(Entity)) {
+	for {
+		select {
+		case entity := <-p.in:
+			add(entity)
+		default:
+			return
+		}
+	}
+}
+
+// send sends a batch and reports its outcome to the callback
+func (p *Producer) send(batch []Entity) {
+	outcome := BatchOutcome{
+		RequestID: uuid.NewString(),
+		Entities:  batch,
+	}
+
+	opts := append(append([]IngestOption(nil), p.ingestOpts...), WithRequestID(outcome.RequestID))
+	p.logger.Debug("Sending batch", logAttrRequestID, outcome.RequestID, logAttrEntityCount, len(batch))
+	outcome.Response, outcome.Err = p.client.Ingest(p.ctx, batch, opts...)
+	if outcome.Err != nil {
+		p.logger.Warn("Batch failed", logAttrRequestID, outcome.RequestID, logAttrEntityCount, len(batch), logAttrError, outcome.Err)
+	}
+
+	if p.callback != nil {
+		p.callback(outcome)
+	}
+}
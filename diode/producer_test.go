@@ -0,0 +1,232 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+type outcomeRecorder struct {
+	mu       sync.Mutex
+	outcomes []BatchOutcome
+}
+
+func (r *outcomeRecorder) record(o BatchOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outcomes = append(r.outcomes, o)
+}
+
+func (r *outcomeRecorder) Outcomes() []BatchOutcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]BatchOutcome(nil), r.outcomes...)
+}
+
+func batchSizes(outcomes []BatchOutcome) []int {
+	var sizes []int
+	for _, o := range outcomes {
+		sizes = append(sizes, len(o.Entities))
+	}
+	return sizes
+}
+
+func TestProducerFlushBySize(t *testing.T) {
+	client := &MockClient{}
+	recorder := &outcomeRecorder{}
+
+	p := NewProducer(client,
+		WithProducerMaxBatchSize(3),
+		WithProducerLinger(time.Hour),
+		WithProducerCallback(recorder.record),
+		WithProducerIngestOptions(WithIngestStream("discovery")),
+	)
+
+	for _, e := range sites(7) {
+		require.NoError(t, p.Add(e))
+	}
+	require.NoError(t, p.Close(context.Background()))
+
+	outcomes := recorder.Outcomes()
+	assert.Equal(t, []int{3, 3, 1}, batchSizes(outcomes))
+
+	calls := client.Calls()
+	require.Len(t, calls, 3)
+	for i, c := range calls {
+		assert.Equal(t, "discovery", c.stream)
+		assert.Equal(t, outcomes[i].RequestID, c.requestID)
+		assert.NoError(t, outcomes[i].Err)
+	}
+}
+
+func TestProducerMaxBatchSizeCapped(t *testing.T) {
+	client := &MockClient{}
+	recorder := &outcomeRecorder{}
+
+	p := NewProducer(client,
+		WithProducerMaxBatchSize(DefaultBatchMaxEntities+1),
+		WithProducerLinger(time.Hour),
+		WithProducerCallback(recorder.record),
+	)
+
+	for _, e := range sites(DefaultBatchMaxEntities + 1) {
+		require.NoError(t, p.Add(e))
+	}
+	require.NoError(t, p.Close(context.Background()))
+
+	assert.Equal(t, []int{DefaultBatchMaxEntities, 1}, batchSizes(recorder.Outcomes()))
+}
+
+func TestProducerFlushByBytes(t *testing.T) {
+	client := &MockClient{}
+	recorder := &outcomeRecorder{}
+
+	large := &Site{Name: String(strings.Repeat("a", 200))}
+	p := NewProducer(client,
+		WithProducerMaxBatchBytes(2*entityWireSize(large)),
+		WithProducerLinger(time.Hour),
+		WithProducerCallback(recorder.record),
+	)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, p.Add(large))
+	}
+	require.NoError(t, p.Close(context.Background()))
+
+	assert.Equal(t, []int{2, 2, 1}, batchSizes(recorder.Outcomes()))
+}
+
+func TestProducerFlushByLinger(t *testing.T) {
+	client := &MockClient{}
+	sent := make(chan BatchOutcome, 1)
+
+	p := NewProducer(client,
+		WithProducerLinger(10*time.Millisecond),
+		WithProducerCallback(func(o BatchOutcome) { sent <- o }),
+	)
+	defer func() {
+		require.NoError(t, p.Close(context.Background()))
+	}()
+
+	require.NoError(t, p.Add(&Site{Name: String("site-1")}))
+
+	select {
+	case o := <-sent:
+		assert.Len(t, o.Entities, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch not sent after linger time")
+	}
+}
+
+func TestProducerFlush(t *testing.T) {
+	client := &MockClient{}
+	recorder := &outcomeRecorder{}
+
+	p := NewProducer(client, WithProducerLinger(time.Hour), WithProducerCallback(recorder.record))
+
+	for _, e := range sites(4) {
+		require.NoError(t, p.Add(e))
+	}
+	require.NoError(t, p.Flush(context.Background()))
+	assert.Equal(t, []int{4}, batchSizes(recorder.Outcomes()))
+
+	require.NoError(t, p.Flush(context.Background()))
+	assert.Len(t, recorder.Outcomes(), 1)
+
+	require.NoError(t, p.Close(context.Background()))
+	require.NoError(t, p.Close(context.Background()))
+
+	assert.Equal(t, ErrProducerClosed, p.Add(&Site{Name: String("site-5")}))
+	assert.Equal(t, ErrProducerClosed, p.Flush(context.Background()))
+}
+
+func TestProducerBackPressure(t *testing.T) {
+	release := make(chan struct{})
+	client := &MockClient{
+		ingestFunc: func(_ []Entity) (*diodepb.IngestResponse, error) {
+			<-release
+			return &diodepb.IngestResponse{}, nil
+		},
+	}
+
+	p := NewProducer(client, WithProducerMaxBatchSize(1), WithProducerBufferSize(1))
+
+	// the first entity is being sent, the second one fills the buffer
+	require.NoError(t, p.Add(&Site{Name: String("site-1")}))
+	require.Eventually(t, func() bool { return len(client.Calls()) == 1 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, p.Add(&Site{Name: String("site-2")}))
+
+	added := make(chan error)
+	go func() {
+		added <- p.Add(&Site{Name: String("site-3")})
+	}()
+
+	select {
+	case <-added:
+		t.Fatal("Add did not block while the buffer was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-added)
+	require.NoError(t, p.Close(context.Background()))
+	assert.Len(t, client.Calls(), 3)
+}
+
+func TestProducerCloseTimeout(t *testing.T) {
+	client := &MockClient{}
+	client.ingestFunc = func(_ []Entity) (*diodepb.IngestResponse, error) {
+		time.Sleep(100 * time.Millisecond)
+		return nil, errors.New("unavailable")
+	}
+	recorder := &outcomeRecorder{}
+
+	p := NewProducer(client, WithProducerMaxBatchSize(1), WithProducerCallback(recorder.record))
+	for _, e := range sites(3) {
+		require.NoError(t, p.Add(e))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, p.Close(ctx), context.DeadlineExceeded)
+	for _, o := range recorder.Outcomes() {
+		assert.Error(t, o.Err)
+	}
+}
+
+func TestProducerConcurrentAdd(t *testing.T) {
+	client := &MockClient{}
+	recorder := &outcomeRecorder{}
+
+	p := NewProducer(client, WithProducerMaxBatchSize(7), WithProducerBufferSize(4), WithProducerCallback(recorder.record))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, e := range sites(20) {
+				if err := p.Add(e); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, p.Close(context.Background()))
+
+	var total int
+	for _, size := range batchSizes(recorder.Outcomes()) {
+		total += size
+	}
+	assert.Equal(t, 200, total)
+}
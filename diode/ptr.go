@@ -49,3 +49,21 @@ func Float32(v float32) *float32 {
 func Float64(v float64) *float64 {
 	return &v
 }
+
+// nonZeroPtr returns a pointer to the value passed in, nil for the zero value.
+func nonZeroPtr[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
+
+// clonePtr returns a pointer to a copy of the value pointed to, nil for nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -75,3 +75,20 @@ func TestFloat64(t *testing.T) {
 	require.NotNil(t, ptr)
 	require.Equal(t, val, *ptr)
 }
+
+func TestNonZeroPtr(t *testing.T) {
+	require.Nil(t, nonZeroPtr(""))
+	require.Nil(t, nonZeroPtr(int32(0)))
+	ptr := nonZeroPtr("value")
+	require.NotNil(t, ptr)
+	require.Equal(t, "value", *ptr)
+}
+
+func TestClonePtr(t *testing.T) {
+	require.Nil(t, clonePtr[string](nil))
+	val := "value"
+	ptr := clonePtr(&val)
+	require.NotNil(t, ptr)
+	require.Equal(t, val, *ptr)
+	require.NotSame(t, &val, ptr)
+}
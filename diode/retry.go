@@ -0,0 +1,100 @@
+package diode
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures retries of ingest requests failing with transient gRPC errors
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, values below 2 disable retries
+	MaxAttempts int
+
+	// InitialBackoff is the backoff before the first retry, doubled for each subsequent retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the backoff between retries
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of the backoff randomly added or subtracted, between 0 and 1
+	Jitter float64
+
+	// RetryableCodes are the gRPC status codes of errors that are retried
+	RetryableCodes []codes.Code
+}
+
+// DefaultRetryPolicy returns a retry policy suitable for transient ingester service outages
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted},
+	}
+}
+
+// WithRetryPolicy sets the retry policy for ingest requests, failed requests are not retried by default
+//
+// Retried requests keep their request ID so that the ingester service can deduplicate them.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *GRPCClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// retryable reports whether the error is retried by the policy
+func (p RetryPolicy) retryable(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	return slices.Contains(p.RetryableCodes, s.Code())
+}
+
+// backoff returns the backoff after the given failed attempt, starting at 1
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(2, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	if jitter > 0 {
+		d += d * jitter * (2*rand.Float64() - 1)
+	}
+
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	return time.Duration(d)
+}
+
+// retry calls fn until it succeeds, fails with an error that is not retryable, or the attempts are exhausted
+func (p RetryPolicy) retry(ctx context.Context, fn func(attempt int) error, onRetry func(attempt int, backoff time.Duration, err error)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		backoff := p.backoff(attempt)
+		if onRetry != nil {
+			onRetry(attempt, backoff, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+// failures returns n scripted responses failing with the status code
+func failures(n int, code codes.Code) []diodetest.Response {
+	responses := make([]diodetest.Response, n)
+	for i := range responses {
+		responses[i] = diodetest.Response{Err: status.Error(code, "transient failure")}
+	}
+	return responses
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 800*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, time.Second, policy.backoff(5))
+	assert.Equal(t, time.Second, policy.backoff(50))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.backoff(2)
+		assert.GreaterOrEqual(t, backoff, 100*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 300*time.Millisecond)
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	assert.True(t, policy.retryable(status.Error(codes.Unavailable, "unavailable")))
+	assert.True(t, policy.retryable(status.Error(codes.ResourceExhausted, "resource exhausted")))
+	assert.False(t, policy.retryable(status.Error(codes.InvalidArgument, "invalid argument")))
+	assert.False(t, policy.retryable(errors.New("not a status error")))
+}
+
+func TestIngestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Jitter:         0.1,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}
+
+	tests := []struct {
+		desc         string
+		failures     int
+		code         codes.Code
+		clientOpts   []ClientOption
+		wantAttempts int
+		wantCode     codes.Code
+	}{
+		{
+			desc:         "retries disabled by default",
+			failures:     1,
+			code:         codes.Unavailable,
+			wantAttempts: 1,
+			wantCode:     codes.Unavailable,
+		},
+		{
+			desc:         "succeeds after retries",
+			failures:     2,
+			code:         codes.Unavailable,
+			clientOpts:   []ClientOption{WithRetryPolicy(policy)},
+			wantAttempts: 3,
+			wantCode:     codes.OK,
+		},
+		{
+			desc:         "attempts exhausted",
+			failures:     5,
+			code:         codes.Unavailable,
+			clientOpts:   []ClientOption{WithRetryPolicy(policy)},
+			wantAttempts: 3,
+			wantCode:     codes.Unavailable,
+		},
+		{
+			desc:         "non-retryable code",
+			failures:     5,
+			code:         codes.InvalidArgument,
+			clientOpts:   []ClientOption{WithRetryPolicy(policy)},
+			wantAttempts: 1,
+			wantCode:     codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t, diodetest.WithResponses(failures(tt.failures, tt.code)...))
+
+			opts := append([]ClientOption{WithAPIKey("abcde")}, tt.clientOpts...)
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", opts...)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			resp, err := client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.NotNil(t, resp)
+			}
+
+			reqs := server.Requests()
+			require.Len(t, reqs, tt.wantAttempts)
+			for _, req := range reqs {
+				assert.Equal(t, reqs[0].GetId(), req.GetId())
+			}
+		})
+	}
+}
+
+func TestIngestRetryCanceledContext(t *testing.T) {
+	server := diodetest.Start(t, diodetest.WithResponses(failures(5, codes.Unavailable)...))
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithRetryPolicy(policy))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Ingest(ctx, []Entity{&Site{Name: String("site-1")}})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	server.AssertRequestCount(t, 1)
+}
@@ -0,0 +1,101 @@
+package diode
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SlugMaxLength is the maximum length of the slugs of NetBox objects
+const SlugMaxLength = 100
+
+// slugSourceFields are the fields slugs are generated from, in order of preference
+var slugSourceFields = []protoreflect.Name{"name", "model"}
+
+// WithSlugGeneration enables filling the empty slugs of entities and all entities nested in them from their name, or
+// the model of device types, before validating and sending them, slugs are not generated by default
+//
+// The entities passed to Ingest are not modified, slugs are only set in the converted messages.
+func WithSlugGeneration() ClientOption {
+	return WithNormalizers(SlugNormalizer())
+}
+
+// Slugify returns the slug of a name as generated by NetBox, e.g. "Data Center (Zürich) 1.2" becomes
+// "data-center-zurich-1-2"
+//
+// Accented letters are transliterated to their base letters, characters other than ASCII letters, digits, underscores,
+// hyphens, dots and whitespace are removed, leading and trailing whitespace and dots are trimmed, runs of hyphens,
+// dots and whitespace are collapsed into a hyphen, and the result is lowercased and truncated to SlugMaxLength
+// characters.
+func Slugify(name string) string {
+	var kept strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case r < unicode.MaxASCII && (isSlugChar(r) || r == '.' || unicode.IsSpace(r)):
+			kept.WriteRune(r)
+		case unicode.IsSpace(r):
+			kept.WriteRune(' ')
+		}
+	}
+
+	trimmed := strings.TrimFunc(kept.String(), func(r rune) bool {
+		return r == '.' || unicode.IsSpace(r)
+	})
+
+	var slug strings.Builder
+	separator := false
+	for _, r := range trimmed {
+		if r == '-' || r == '.' || unicode.IsSpace(r) {
+			separator = true
+			continue
+		}
+		if separator {
+			slug.WriteByte('-')
+			separator = false
+		}
+		slug.WriteRune(unicode.ToLower(r))
+	}
+	if separator {
+		slug.WriteByte('-')
+	}
+
+	s := slug.String()
+	if len(s) > SlugMaxLength {
+		s = s[:SlugMaxLength]
+	}
+	return s
+}
+
+// isSlugChar reports whether an ASCII character is allowed in slugs
+func isSlugChar(r rune) bool {
+	return r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+// SlugNormalizer returns a normalizer setting the empty slugs of messages from their name, or the model of device
+// types, see Slugify
+func SlugNormalizer() Normalizer {
+	return NormalizerFunc(func(m proto.Message) {
+		fillSlug(m.ProtoReflect())
+	})
+}
+
+// fillSlug sets the empty slug of a message from its name or model
+func fillSlug(m protoreflect.Message) {
+	fields := m.Descriptor().Fields()
+	slug := fields.ByName("slug")
+	if slug == nil || slug.Kind() != protoreflect.StringKind || m.Get(slug).String() != "" {
+		return
+	}
+
+	for _, name := range slugSourceFields {
+		if fd := fields.ByName(name); fd != nil && fd.Kind() == protoreflect.StringKind {
+			if s := Slugify(m.Get(fd).String()); s != "" {
+				m.Set(slug, protoreflect.ValueOfString(s))
+			}
+			return
+		}
+	}
+}
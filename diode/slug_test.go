@@ -0,0 +1,110 @@
+package diode
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		desc string
+		name string
+		want string
+	}{
+		{desc: "lowercased", name: "DC1", want: "dc1"},
+		{desc: "spaces", name: "Data Center 1", want: "data-center-1"},
+		{desc: "transliterated", name: "Zürich Café", want: "zurich-cafe"},
+		{desc: "removed characters", name: "Rack (A/B) #1", want: "rack-ab-1"},
+		{desc: "collapsed separators", name: "core -- switch . 1", want: "core-switch-1"},
+		{desc: "dots", name: "ISR4321.v2", want: "isr4321-v2"},
+		{desc: "trimmed", name: " .leaf 1. ", want: "leaf-1"},
+		{desc: "underscores kept", name: "leaf_1", want: "leaf_1"},
+		{desc: "leading hyphen kept", name: "-leaf", want: "-leaf"},
+		{desc: "trailing hyphen kept", name: "abc-", want: "abc-"},
+		{desc: "trailing separators collapsed", name: "abc -.", want: "abc-"},
+		{desc: "whitespace", name: "leaf 1\t2", want: "leaf-1-2"},
+		{desc: "non latin removed", name: "東京 dc", want: "dc"},
+		{desc: "truncated", name: strings.Repeat("a", SlugMaxLength+10), want: strings.Repeat("a", SlugMaxLength)},
+		{desc: "empty", name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, Slugify(tt.name))
+		})
+	}
+}
+
+func TestSlugNormalizer(t *testing.T) {
+	device := &Device{
+		Name: String("sw1"),
+		Site: &Site{Name: String("Data Center 1"), Tags: []*Tag{{Name: String("Prod")}, {Name: String("edge"), Slug: String("custom")}}},
+		DeviceType: &DeviceType{
+			Model:        String("DCS-7050"),
+			Manufacturer: &Manufacturer{Name: String("Arista Networks")},
+		},
+		Role:     &Role{Name: String("Leaf Switch")},
+		Platform: &Platform{Name: String("")},
+	}
+
+	m := device.ConvertToProtoEntity()
+	normalize(m.ProtoReflect(), []Normalizer{SlugNormalizer()})
+
+	got := m.GetDevice()
+	assert.Equal(t, "data-center-1", got.GetSite().GetSlug())
+	assert.Equal(t, "prod", got.GetSite().GetTags()[0].GetSlug())
+	assert.Equal(t, "custom", got.GetSite().GetTags()[1].GetSlug())
+	assert.Equal(t, "dcs-7050", got.GetDeviceType().GetSlug())
+	assert.Equal(t, "arista-networks", got.GetDeviceType().GetManufacturer().GetSlug())
+	assert.Equal(t, "leaf-switch", got.GetRole().GetSlug())
+	assert.Empty(t, got.GetPlatform().GetSlug())
+
+	assert.Nil(t, device.Site.Slug, "entity must not be modified")
+}
+
+func TestIngestSlugGeneration(t *testing.T) {
+	tests := []struct {
+		desc       string
+		clientOpts []ClientOption
+		want       *diodepb.Site
+	}{
+		{
+			desc: "disabled by default",
+			want: &diodepb.Site{Name: "Data Center 1"},
+		},
+		{
+			desc:       "enabled",
+			clientOpts: []ClientOption{WithSlugGeneration()},
+			want:       &diodepb.Site{Name: "Data Center 1", Slug: "data-center-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			opts := append([]ClientOption{WithAPIKey("abcde"), WithDefaultTimestamp(false)}, tt.clientOpts...)
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", opts...)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("Data Center 1")}})
+			require.NoError(t, err)
+
+			requests := server.Requests()
+			require.Len(t, requests, 1)
+			require.Len(t, requests[0].GetEntities(), 1)
+			assert.True(t, proto.Equal(tt.want, requests[0].GetEntities()[0].GetSite()))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package diode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Snapshot is the state of the last successfully ingested version of each entity
+type Snapshot struct {
+	// Hashes are the hashes of the canonical proto bytes of the entities by identity
+	Hashes map[Identity]string
+
+	// FullSync is the time of the last full resync, zero if there was none
+	FullSync time.Time
+}
+
+// clone returns a deep copy of the snapshot
+func (s *Snapshot) clone() *Snapshot {
+	c := &Snapshot{Hashes: make(map[Identity]string, len(s.Hashes)), FullSync: s.FullSync}
+	maps.Copy(c.Hashes, s.Hashes)
+	return c
+}
+
+// SnapshotStore persists the snapshot of a Differ between runs
+type SnapshotStore interface {
+	// Load returns the last saved snapshot, or an empty snapshot if none has been saved
+	Load() (*Snapshot, error)
+
+	// Save replaces the saved snapshot
+	Save(*Snapshot) error
+}
+
+// MemorySnapshotStore keeps the snapshot in memory, e.g. for long-running agents that resync fully on restart
+type MemorySnapshotStore struct {
+	mu       sync.Mutex
+	snapshot *Snapshot
+}
+
+// NewMemorySnapshotStore creates a new empty in-memory snapshot store
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{}
+}
+
+// Load returns a copy of the saved snapshot
+func (s *MemorySnapshotStore) Load() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot == nil {
+		return &Snapshot{Hashes: make(map[Identity]string)}, nil
+	}
+	return s.snapshot.clone(), nil
+}
+
+// Save saves a copy of the snapshot
+func (s *MemorySnapshotStore) Save(snapshot *Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = snapshot.clone()
+	return nil
+}
+
+// FileSnapshotStore keeps the snapshot in a JSON file, replaced atomically on every save
+type FileSnapshotStore struct {
+	path string
+}
+
+// NewFileSnapshotStore creates a snapshot store persisting the snapshot to the file at the given path
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{path: path}
+}
+
+// snapshotFile is the JSON representation of a snapshot
+type snapshotFile struct {
+	FullSync time.Time           `json:"full_sync,omitempty"`
+	Entities []snapshotFileEntry `json:"entities"`
+}
+
+// snapshotFileEntry is the JSON representation of the hash of an entity
+type snapshotFileEntry struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+	Hash string `json:"hash"`
+}
+
+// Load reads the snapshot from the file, returning an empty snapshot if the file does not exist
+func (s *FileSnapshotStore) Load() (*Snapshot, error) {
+	snapshot := &Snapshot{Hashes: make(map[Identity]string)}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f snapshotFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid snapshot file %s: %w", s.path, err)
+	}
+
+	snapshot.FullSync = f.FullSync
+	for _, e := range f.Entities {
+		snapshot.Hashes[Identity{Type: e.Type, Key: e.Key}] = e.Hash
+	}
+	return snapshot, nil
+}
+
+// Save writes the snapshot to a temporary file renamed over the file
+func (s *FileSnapshotStore) Save(snapshot *Snapshot) error {
+	f := snapshotFile{FullSync: snapshot.FullSync, Entities: make([]snapshotFileEntry, 0, len(snapshot.Hashes))}
+	for id, hash := range snapshot.Hashes {
+		f.Entities = append(f.Entities, snapshotFileEntry{Type: id.Type, Key: id.Key, Hash: hash})
+	}
+	sort.Slice(f.Entities, func(i, j int) bool {
+		if f.Entities[i].Type != f.Entities[j].Type {
+			return f.Entities[i].Type < f.Entities[j].Type
+		}
+		return f.Entities[i].Key < f.Entities[j].Key
+	})
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package diode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Hashes: map[Identity]string{
+			{Type: "site", Key: `name="dc1"`}:                     "a1",
+			{Type: "device", Key: `name="sw1",site=(name="dc1")`}: "b2",
+		},
+		FullSync: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestFileSnapshotStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	store := NewFileSnapshotStore(path)
+
+	snapshot, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Hashes)
+	assert.True(t, snapshot.FullSync.IsZero())
+
+	require.NoError(t, store.Save(testSnapshot()))
+
+	snapshot, err = NewFileSnapshotStore(path).Load()
+	require.NoError(t, err)
+	assert.Equal(t, testSnapshot().Hashes, snapshot.Hashes)
+	assert.True(t, testSnapshot().FullSync.Equal(snapshot.FullSync))
+
+	require.NoError(t, store.Save(&Snapshot{Hashes: map[Identity]string{}}))
+	snapshot, err = store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Hashes)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files must be removed")
+}
+
+func TestFileSnapshotStoreInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := NewFileSnapshotStore(path).Load()
+	require.ErrorContains(t, err, "invalid snapshot file")
+}
+
+func TestMemorySnapshotStore(t *testing.T) {
+	store := NewMemorySnapshotStore()
+
+	snapshot, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, snapshot.Hashes)
+
+	saved := testSnapshot()
+	require.NoError(t, store.Save(saved))
+	saved.Hashes[Identity{Type: "site", Key: `name="dc2"`}] = "c3"
+
+	snapshot, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, testSnapshot(), snapshot)
+
+	snapshot.Hashes[Identity{Type: "site", Key: `name="dc3"`}] = "d4"
+	snapshot, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, testSnapshot(), snapshot)
+}
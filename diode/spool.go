@@ -0,0 +1,359 @@
+package diode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+const (
+	// DefaultSpoolMaxBytes is the default maximum total size of the spool directory
+	DefaultSpoolMaxBytes = 100 * 1024 * 1024
+
+	// DefaultSpoolMaxAge is the default maximum age of a spooled request
+	DefaultSpoolMaxAge = 7 * 24 * time.Hour
+
+	spoolFileExt         = ".req"
+	spoolTmpFileExt      = ".tmp"
+	spoolRejectedFileExt = ".rejected"
+	spoolLockFileName    = ".lock"
+)
+
+// spool persists ingest requests to a directory until they have been sent successfully
+//
+// Each request is written as a length-prefixed protobuf message to its own file, named after the time it was spooled
+// and its request ID so that files sort in spooling order.
+type spool struct {
+	// The logger for the spool
+	logger *slog.Logger
+
+	// Spool directory
+	dir string
+
+	// Maximum total size of the spooled requests
+	maxBytes int64
+
+	// Maximum age of a spooled request
+	maxAge time.Duration
+
+	// Clock used for naming and expiring spooled requests
+	now func() time.Time
+
+	// Lock file held while the spool is in use
+	lock *os.File
+
+	// Serializes writes and cap enforcement
+	mu sync.Mutex
+}
+
+// spoolConfig holds the spool settings collected by the client options
+type spoolConfig struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+}
+
+// SpoolOption is a functional option for the spool
+type SpoolOption func(*spoolConfig)
+
+// WithSpoolMaxBytes sets the maximum total size of the spooled requests, the oldest requests are dropped beyond it
+func WithSpoolMaxBytes(n int64) SpoolOption {
+	return func(c *spoolConfig) {
+		c.maxBytes = n
+	}
+}
+
+// WithSpoolMaxAge sets the maximum age of a spooled request, older requests are dropped instead of being replayed
+func WithSpoolMaxAge(d time.Duration) SpoolOption {
+	return func(c *spoolConfig) {
+		c.maxAge = d
+	}
+}
+
+// WithSpool persists ingest requests to the given directory before sending them
+//
+// Requests are deleted once sent successfully. Requests left over by a previous client, because the ingester service
+// was unreachable or the process exited, are replayed in the background by NewClient with their original request IDs
+// and producer metadata. Requests rejected by the ingester service, e.g. with InvalidArgument or Unauthenticated, are
+// not replayed: their files are renamed with the .rejected extension and dropped after the maximum age.
+//
+// A spool directory is used by a single client at a time, NewClient fails if another client holds its lock file. The
+// lock is not enforced on platforms without flock, e.g. Windows, where sharing a directory is unsupported.
+func WithSpool(dir string, opts ...SpoolOption) ClientOption {
+	return func(c *GRPCClient) {
+		c.spoolConfig = &spoolConfig{
+			dir:      dir,
+			maxBytes: DefaultSpoolMaxBytes,
+			maxAge:   DefaultSpoolMaxAge,
+		}
+		for _, o := range opts {
+			o(c.spoolConfig)
+		}
+	}
+}
+
+// newSpool creates a spool in the configured directory, creating the directory if needed
+func newSpool(cfg *spoolConfig, logger *slog.Logger) (*spool, error) {
+	if cfg.dir == "" {
+		return nil, errors.New("spool directory is required")
+	}
+
+	if err := os.MkdirAll(cfg.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	lock, err := lockSpoolDir(cfg.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &spool{
+		logger:   logger,
+		dir:      cfg.dir,
+		maxBytes: cfg.maxBytes,
+		maxAge:   cfg.maxAge,
+		now:      time.Now,
+		lock:     lock,
+	}, nil
+}
+
+// close releases the lock of the spool directory
+func (s *spool) close() error {
+	return s.lock.Close()
+}
+
+// spoolable reports whether a request that failed with the error is kept in the spool to be replayed, i.e. whether it
+// failed with a transient or transport error rather than being rejected by the ingester service
+func spoolable(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.ResourceExhausted, codes.Aborted,
+		codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// write persists the request and returns the path of its spool file
+func (s *spool) write(req *diodepb.IngestRequest) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := fmt.Sprintf("%020d-%s%s", s.now().UnixNano(), req.GetId(), spoolFileExt)
+	path := filepath.Join(s.dir, name)
+	tmpPath := path + spoolTmpFileExt
+
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(f)
+	_, err = protodelim.MarshalTo(w, req)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err == nil {
+		err = s.syncDir()
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+
+	s.enforceMaxBytes(path)
+
+	return path, nil
+}
+
+// remove deletes the spool file of a request that has been sent successfully
+func (s *spool) remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.syncDir()
+}
+
+// reject moves aside the spool file of a request rejected by the ingester service, so that it is not replayed
+func (s *spool) reject(path string) error {
+	if err := os.Rename(path, path+spoolRejectedFileExt); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.syncDir()
+}
+
+// read reads the request persisted in a spool file
+func (s *spool) read(path string) (*diodepb.IngestRequest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	req := &diodepb.IngestRequest{}
+	if err := protodelim.UnmarshalFrom(bufio.NewReader(f), req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// pending returns the spool files in spooling order, dropping expired and expired rejected requests and incomplete
+// writes
+func (s *spool) pending() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(s.dir, entry.Name())
+
+		if strings.HasSuffix(entry.Name(), spoolTmpFileExt) {
+			s.logger.Debug("Removing incomplete spool file", logAttrSpoolFile, path)
+			_ = os.Remove(path)
+			continue
+		}
+
+		if strings.HasSuffix(entry.Name(), spoolRejectedFileExt) {
+			if s.expired(entry.Name()) {
+				s.logger.Debug("Removing expired rejected request", logAttrSpoolFile, path)
+				_ = os.Remove(path)
+			}
+			continue
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileExt) {
+			continue
+		}
+
+		if s.expired(entry.Name()) {
+			s.logger.Warn("Dropping expired spooled request", logAttrSpoolFile, path)
+			_ = os.Remove(path)
+			continue
+		}
+
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// expired reports whether the spool file is older than the maximum age
+func (s *spool) expired(name string) bool {
+	if s.maxAge <= 0 {
+		return false
+	}
+
+	ts, _, ok := strings.Cut(name, "-")
+	if !ok {
+		return false
+	}
+
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return s.now().Sub(time.Unix(0, nanos)) > s.maxAge
+}
+
+// enforceMaxBytes drops the oldest spooled requests, except the given one, until the spool fits its maximum size
+func (s *spool) enforceMaxBytes(keep string) {
+	if s.maxBytes <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.logger.Warn("Failed to read spool directory", logAttrError, err)
+		return
+	}
+
+	type spooled struct {
+		path string
+		size int64
+	}
+
+	var files []spooled
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), spoolFileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, spooled{path: filepath.Join(s.dir, entry.Name()), size: info.Size()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	for _, f := range files {
+		if total <= s.maxBytes {
+			return
+		}
+		if f.path == keep {
+			continue
+		}
+		s.logger.Warn("Dropping spooled request over the spool size limit", logAttrSpoolFile, f.path)
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+}
+
+// syncDir flushes the spool directory entries to disk
+func (s *spool) syncDir() error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(s.dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = d.Close()
+	}()
+
+	return d.Sync()
+}
@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package diode
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// spoolLocking reports whether spool directories are locked on this platform
+const spoolLocking = true
+
+// lockSpoolDir opens and locks the lock file of the spool directory, failing if another client holds it
+func lockSpoolDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, spoolLockFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("spool directory %s is used by another client", dir)
+		}
+		return nil, fmt.Errorf("failed to lock spool directory: %w", err)
+	}
+
+	return f, nil
+}
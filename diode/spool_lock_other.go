@@ -0,0 +1,21 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package diode
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// spoolLocking reports whether spool directories are locked on this platform
+const spoolLocking = false
+
+// lockSpoolDir opens the lock file of the spool directory without locking it, as flock is not available
+func lockSpoolDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, spoolLockFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool lock file: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,265 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func newTestSpool(t *testing.T, opts ...SpoolOption) *spool {
+	t.Helper()
+
+	cfg := &spoolConfig{dir: filepath.Join(t.TempDir(), "spool")}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	s, err := newSpool(cfg, newLogger(io.Discard, LogFormatJSON))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.close()
+	})
+	return s
+}
+
+func newTestSpoolAt(t *testing.T, dir string) *spool {
+	t.Helper()
+
+	s, err := newSpool(&spoolConfig{dir: dir}, newLogger(io.Discard, LogFormatJSON))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.close()
+	})
+	return s
+}
+
+func newTestIngestRequest() *diodepb.IngestRequest {
+	return &diodepb.IngestRequest{
+		Id:                 uuid.NewString(),
+		Stream:             "latest",
+		Entities:           []*diodepb.Entity{(&Site{Name: String("site-1")}).ConvertToProtoEntity()},
+		ProducerAppName:    "my-producer",
+		ProducerAppVersion: "0.1.0",
+		SdkName:            SDKName,
+		SdkVersion:         SDKVersion,
+	}
+}
+
+func TestSpoolWriteReadRemove(t *testing.T) {
+	s := newTestSpool(t)
+	req := newTestIngestRequest()
+
+	path, err := s.write(req)
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+	assert.NoFileExists(t, path+spoolTmpFileExt)
+
+	got, err := s.read(path)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(req, got))
+
+	paths, err := s.pending()
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, paths)
+
+	require.NoError(t, s.remove(path))
+	assert.NoFileExists(t, path)
+	require.NoError(t, s.remove(path))
+}
+
+func TestSpoolPending(t *testing.T) {
+	s := newTestSpool(t, WithSpoolMaxAge(time.Hour))
+
+	now := time.Now()
+	var paths []string
+	for _, offset := range []time.Duration{-2 * time.Hour, -2 * time.Minute, -time.Minute} {
+		s.now = func() time.Time { return now.Add(offset) }
+		path, err := s.write(newTestIngestRequest())
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+	s.now = func() time.Time { return now }
+
+	incomplete := filepath.Join(s.dir, "incomplete"+spoolFileExt+spoolTmpFileExt)
+	require.NoError(t, os.WriteFile(incomplete, []byte("partial"), 0o600))
+
+	pending, err := s.pending()
+	require.NoError(t, err)
+	assert.Equal(t, paths[1:], pending)
+	assert.NoFileExists(t, paths[0])
+	assert.NoFileExists(t, incomplete)
+}
+
+func TestSpoolMaxBytes(t *testing.T) {
+	size := int64(proto.Size(newTestIngestRequest())) + 1
+	s := newTestSpool(t, WithSpoolMaxBytes(2*size))
+
+	var paths []string
+	for i := 0; i < 3; i++ {
+		s.now = func() time.Time { return time.Unix(int64(i), 0) }
+		path, err := s.write(newTestIngestRequest())
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+
+	pending, err := s.pending()
+	require.NoError(t, err)
+	assert.Equal(t, paths[1:], pending)
+}
+
+func TestIngestSpoolReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	server := diodetest.Start(t, diodetest.WithResponses(diodetest.Response{Err: status.Error(codes.Unavailable, "unavailable")}))
+
+	// the ingester service is unavailable, the request stays in the spool
+	client, err := NewClient(server.Target(), "first-producer", "0.1.0", WithAPIKey("abcde"), WithSpool(dir))
+	require.NoError(t, err)
+
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}}, WithIngestStream("discovery"))
+	require.Error(t, err)
+	require.NoError(t, client.Close())
+
+	spooled, err := filepath.Glob(filepath.Join(dir, "*"+spoolFileExt))
+	require.NoError(t, err)
+	require.Len(t, spooled, 1)
+
+	// the next client replays the request with its original metadata
+	client, err = NewClient(server.Target(), "second-producer", "0.2.0", WithAPIKey("abcde"), WithSpool(dir))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(server.Requests()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, client.Close())
+
+	assert.Equal(t, server.Requests()[0].GetId(), server.Requests()[1].GetId())
+	req := server.Requests()[1]
+	assert.Equal(t, "first-producer", req.GetProducerAppName())
+	assert.Equal(t, "0.1.0", req.GetProducerAppVersion())
+	assert.Equal(t, "discovery", req.GetStream())
+	assert.Contains(t, filepath.Base(spooled[0]), req.GetId())
+
+	spooled, err = filepath.Glob(filepath.Join(dir, "*"+spoolFileExt))
+	require.NoError(t, err)
+	assert.Empty(t, spooled)
+}
+
+func TestIngestSpoolRemovesSentRequests(t *testing.T) {
+	dir := t.TempDir()
+	server := diodetest.Start(t)
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithSpool(dir))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+	require.NoError(t, err)
+	assert.Len(t, server.Requests(), 1)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.Equal(t, spoolLockFileName, entry.Name())
+	}
+}
+
+func TestSpoolLock(t *testing.T) {
+	if !spoolLocking {
+		t.Skip("spool directories are not locked on this platform")
+	}
+
+	s := newTestSpool(t)
+
+	_, err := newSpool(&spoolConfig{dir: s.dir}, newLogger(io.Discard, LogFormatJSON))
+	require.ErrorContains(t, err, "is used by another client")
+
+	require.NoError(t, s.close())
+	other, err := newSpool(&spoolConfig{dir: s.dir}, newLogger(io.Discard, LogFormatJSON))
+	require.NoError(t, err)
+	require.NoError(t, other.close())
+}
+
+func TestSpoolable(t *testing.T) {
+	tests := []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{desc: "transport error", err: errors.New("connection refused"), want: true},
+		{desc: "unavailable", err: status.Error(codes.Unavailable, "unavailable"), want: true},
+		{desc: "deadline exceeded", err: status.Error(codes.DeadlineExceeded, "deadline exceeded"), want: true},
+		{desc: "invalid argument", err: status.Error(codes.InvalidArgument, "invalid"), want: false},
+		{desc: "unauthenticated", err: status.Error(codes.Unauthenticated, "unauthenticated"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, spoolable(tt.err))
+		})
+	}
+}
+
+func TestIngestSpoolRejectedRequest(t *testing.T) {
+	dir := t.TempDir()
+	server := diodetest.Start(t, diodetest.WithResponses(diodetest.Response{Err: status.Error(codes.InvalidArgument, "invalid")}))
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithSpool(dir))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.Ingest(context.Background(), []Entity{&Site{Name: String("site-1")}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	spooled, err := filepath.Glob(filepath.Join(dir, "*"+spoolFileExt))
+	require.NoError(t, err)
+	assert.Empty(t, spooled)
+
+	rejected, err := filepath.Glob(filepath.Join(dir, "*"+spoolFileExt+spoolRejectedFileExt))
+	require.NoError(t, err)
+	assert.Len(t, rejected, 1)
+}
+
+func TestIngestSpoolReplayContinuesPastRejectedRequest(t *testing.T) {
+	s := newTestSpool(t)
+	var paths []string
+	for i := 0; i < 2; i++ {
+		s.now = func() time.Time { return time.Now().Add(time.Duration(i) * time.Millisecond) }
+		path, err := s.write(newTestIngestRequest())
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+	require.NoError(t, s.close())
+
+	server := diodetest.Start(t, diodetest.WithResponses(diodetest.Response{Err: status.Error(codes.InvalidArgument, "invalid")}))
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithSpool(s.dir))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(server.Calls()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, client.Close())
+
+	assert.NoFileExists(t, paths[0])
+	assert.FileExists(t, paths[0]+spoolRejectedFileExt)
+	assert.NoFileExists(t, paths[1])
+
+	pending, err := newTestSpoolAt(t, s.dir).pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
@@ -0,0 +1,152 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// StreamingClient is a Client able to send entities over a client-streaming ingest RPC, the clients created by
+// NewClient implement it
+type StreamingClient interface {
+	Client
+
+	// IngestStreaming sends the entities incrementally over a single client-streaming ingest RPC
+	IngestStreaming(context.Context, []Entity, ...IngestOption) (*diodepb.IngestResponse, error)
+}
+
+// IngestStreaming sends the entities incrementally over a single client-streaming ingest RPC
+//
+// The entities are prepared as by Ingest and sent as a stream of ingest requests bounded by DefaultBatchMaxEntities
+// and DefaultBatchMaxBytes. The first request uses the request ID of the call, the following ones new request IDs.
+//
+// The stream is traced and retried like unary ingest requests. If the server replies Unimplemented, the requests are
+// sent as unary ingest requests instead, and so are the requests of later calls. Clients in dry-run mode or spooling
+// requests always send unary requests, as streams are not spooled. Nothing is sent if there are no entities to send.
+func (g *GRPCClient) IngestStreaming(ctx context.Context, entities []Entity, opts ...IngestOption) (*diodepb.IngestResponse, error) {
+	o, err := g.ingestOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	protoEntities, validationErrs, err := g.prepareEntities(entities, o)
+	if err != nil {
+		return nil, err
+	}
+	if len(protoEntities) == 0 {
+		// nothing to send, not even an empty stream
+		resp := &diodepb.IngestResponse{}
+		if len(validationErrs) > 0 {
+			resp.Errors = validationErrs.messages()
+		}
+		return resp, nil
+	}
+
+	chunks := splitProtoEntities(protoEntities, DefaultBatchMaxEntities, DefaultBatchMaxBytes)
+	reqs := make([]*diodepb.IngestRequest, len(chunks))
+	for i, c := range chunks {
+		id := o.requestID
+		if i > 0 {
+			id = uuid.NewString()
+		}
+		reqs[i] = g.newRequest(id, o.stream, c)
+	}
+
+	var resp *diodepb.IngestResponse
+	if g.recorder != nil || g.spool != nil || g.streamingUnsupported.Load() {
+		resp, err = g.ingestRequests(ctx, reqs)
+	} else {
+		resp, err = g.sendStream(ctx, reqs)
+		if status.Code(err) == codes.Unimplemented {
+			g.streamingUnsupported.Store(true)
+			g.logger.Warn("Streaming ingest not supported by the server, falling back to unary requests",
+				logAttrRequestID, o.requestID, logAttrStream, o.stream, logAttrRequestCount, len(reqs))
+			resp, err = g.ingestRequests(ctx, reqs)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(validationErrs) > 0 {
+		resp.Errors = append(validationErrs.messages(), resp.GetErrors()...)
+	}
+
+	return resp, nil
+}
+
+// sendStream sends the ingest requests over a single client-streaming ingest RPC, retrying it according to the retry
+// policy like unary ingest requests
+func (g *GRPCClient) sendStream(ctx context.Context, reqs []*diodepb.IngestRequest) (*diodepb.IngestResponse, error) {
+	return g.invoke(ctx, ingestStreamMethod, reqs, func(ctx context.Context) (*diodepb.IngestResponse, error) {
+		return g.streamRequests(ctx, reqs)
+	})
+}
+
+// streamRequests opens an ingest stream, sends the requests and returns the response of the server once closed
+func (g *GRPCClient) streamRequests(ctx context.Context, reqs []*diodepb.IngestRequest) (*diodepb.IngestResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := g.streamClient.IngestStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				// the server ended the stream, its status is returned by CloseAndRecv
+				break
+			}
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// ingestRequests sends the ingest requests one by one, combining the errors of their responses
+func (g *GRPCClient) ingestRequests(ctx context.Context, reqs []*diodepb.IngestRequest) (*diodepb.IngestResponse, error) {
+	resp := &diodepb.IngestResponse{}
+	for _, req := range reqs {
+		r, err := g.ingestRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Errors = append(resp.Errors, r.GetErrors()...)
+	}
+	return resp, nil
+}
+
+// splitProtoEntities splits the proto entities into chunks bounded by entity count and marshalled size, see
+// splitEntities
+func splitProtoEntities(entities []*diodepb.Entity, maxEntities int, maxBytes int) [][]*diodepb.Entity {
+	var chunks [][]*diodepb.Entity
+
+	start := 0
+	size := 0
+	for i, entity := range entities {
+		entitySize := protoEntityWireSize(entity)
+
+		count := i - start
+		if count > 0 && (count >= maxEntities || size+entitySize > maxBytes) {
+			chunks = append(chunks, entities[start:i])
+			start = i
+			size = 0
+		}
+		size += entitySize
+	}
+
+	if start < len(entities) {
+		chunks = append(chunks, entities[start:])
+	}
+
+	return chunks
+}
@@ -0,0 +1,222 @@
+package diode
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+func TestIngestStreaming(t *testing.T) {
+	server := diodetest.Start(t, diodetest.WithResponses(diodetest.Response{Errors: []string{"stream error"}}))
+
+	spans := tracetest.NewSpanRecorder()
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithPropagator(propagation.TraceContext{}),
+	)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	requestID := "0b7c6c5e-3b0a-4b8e-9c3a-6d1b1f0e2a4c"
+	resp, err := client.(StreamingClient).IngestStreaming(context.Background(), sites(3), WithRequestID(requestID), WithIngestStream("lab"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stream error"}, resp.GetErrors())
+
+	calls := server.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, requestID, calls[0].Request.GetId())
+	assert.Equal(t, "lab", calls[0].Request.GetStream())
+	assert.Equal(t, "my-producer", calls[0].Request.GetProducerAppName())
+	assert.Len(t, calls[0].Request.GetEntities(), 3)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "diode.v1.IngesterService/IngestStream", ended[0].Name())
+	traceparent := calls[0].Metadata.Get("traceparent")
+	require.Len(t, traceparent, 1)
+	assert.Contains(t, traceparent[0], ended[0].SpanContext().TraceID().String())
+}
+
+func TestIngestStreamingNoEntities(t *testing.T) {
+	server := diodetest.Start(t)
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	resp, err := client.(StreamingClient).IngestStreaming(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetErrors())
+	assert.Empty(t, server.Calls())
+}
+
+func TestIngestStreamingRetry(t *testing.T) {
+	server := diodetest.Start(t, diodetest.WithResponses(diodetest.Response{Err: status.Error(codes.Unavailable, "unavailable")}))
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryableCodes: []codes.Code{codes.Unavailable}}))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.(StreamingClient).IngestStreaming(context.Background(), sites(2))
+	require.NoError(t, err)
+
+	calls := server.Calls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, calls[0].Request.GetId(), calls[1].Request.GetId())
+	assert.False(t, client.(*GRPCClient).streamingUnsupported.Load())
+}
+
+func TestIngestStreamingFallback(t *testing.T) {
+	server := diodetest.Start(t, diodetest.WithoutStreaming())
+
+	client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithValidation(ValidationSkipInvalid))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	entities := []Entity{
+		&Site{Name: String("dc1"), Slug: String("dc1"), Status: String("active")},
+		&Site{Name: String("dc2"), Slug: String("dc2"), Status: String("active")},
+		&Site{Name: String("dc3"), Slug: String("dc3"), Status: String("unknown")},
+	}
+	for i := 0; i < 2; i++ {
+		var skipped ValidationErrors
+		resp, err := client.(StreamingClient).IngestStreaming(context.Background(), entities, WithSkippedEntities(&skipped))
+		require.NoError(t, err)
+		assert.Len(t, resp.GetErrors(), 1)
+		assert.Equal(t, []int{2}, skipped.Indexes())
+	}
+
+	assert.True(t, client.(*GRPCClient).streamingUnsupported.Load())
+	reqs := server.Requests()
+	require.Len(t, reqs, 2)
+	for _, req := range reqs {
+		assert.Len(t, req.GetEntities(), 2)
+	}
+}
+
+func TestMethodStreamInterceptor(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var methods []string
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+
+		if strings.HasSuffix(method, "/IngestStream") {
+			return status.Error(codes.Unimplemented, "unknown method IngestStream")
+		}
+		if err := stream.RecvMsg(&diodepb.IngestRequest{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&diodepb.IngestResponse{})
+	}))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	client, err := NewClient(fmt.Sprintf("grpc://%s/foobar", listener.Addr().String()), "my-producer", "0.1.0", WithAPIKey("abcde"))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, client.Close())
+	}()
+
+	_, err = client.(StreamingClient).IngestStreaming(context.Background(), sites(1))
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"/foobar/diode.v1.IngesterService/IngestStream",
+		"/foobar/diode.v1.IngesterService/Ingest",
+	}, methods)
+}
+
+func TestSplitProtoEntities(t *testing.T) {
+	large := (&Site{Name: String(strings.Repeat("a", 200))}).ConvertToProtoEntity()
+	largeSize := protoEntityWireSize(large)
+
+	protoSites := func(n int) []*diodepb.Entity {
+		entities := make([]*diodepb.Entity, n)
+		for i, site := range sites(n) {
+			entities[i] = site.ConvertToProtoEntity()
+		}
+		return entities
+	}
+
+	tests := []struct {
+		desc        string
+		entities    []*diodepb.Entity
+		maxEntities int
+		maxBytes    int
+		wantCounts  []int
+	}{
+		{
+			desc:        "no entities",
+			entities:    nil,
+			maxEntities: 10,
+			maxBytes:    DefaultBatchMaxBytes,
+			wantCounts:  []int{},
+		},
+		{
+			desc:        "split by count",
+			entities:    protoSites(25),
+			maxEntities: 10,
+			maxBytes:    DefaultBatchMaxBytes,
+			wantCounts:  []int{10, 10, 5},
+		},
+		{
+			desc:        "split by size",
+			entities:    []*diodepb.Entity{large, large, large},
+			maxEntities: 10,
+			maxBytes:    2 * largeSize,
+			wantCounts:  []int{2, 1},
+		},
+		{
+			desc:        "entity larger than max bytes",
+			entities:    []*diodepb.Entity{large, large},
+			maxEntities: 10,
+			maxBytes:    largeSize - 1,
+			wantCounts:  []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			chunks := splitProtoEntities(tt.entities, tt.maxEntities, tt.maxBytes)
+			counts := make([]int, len(chunks))
+			for i, c := range chunks {
+				counts[i] = len(c)
+			}
+			assert.Equal(t, tt.wantCounts, counts)
+		})
+	}
+}
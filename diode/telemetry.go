@@ -0,0 +1,259 @@
+package diode
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+const (
+	// instrumentationName is the name of the tracer and meter of the SDK
+	instrumentationName = "github.com/netboxlabs/diode-sdk-go/diode"
+
+	// ingestMethod is the method of the unary ingest RPC
+	ingestMethod = "Ingest"
+
+	// ingestStreamMethod is the method of the client-streaming ingest RPC
+	ingestStreamMethod = "IngestStream"
+)
+
+// Span and metric attribute keys
+const (
+	attrRequestID    = attribute.Key("diode.request_id")
+	attrStream       = attribute.Key("diode.stream")
+	attrEntityCount  = attribute.Key("diode.entity_count")
+	attrRequestCount = attribute.Key("diode.request_count")
+	attrEntityType   = attribute.Key("diode.entity_type")
+
+	// attrEntityTypeCountPrefix prefixes the per entity type counts of a span, e.g. diode.entity_count.device
+	attrEntityTypeCountPrefix = "diode.entity_count."
+)
+
+// telemetryOptions holds the OpenTelemetry providers collected by the client options
+type telemetryOptions struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider for the client, the global one is used by default
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(c *GRPCClient) {
+		c.telemetryOptions.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the OpenTelemetry meter provider for the client, the global one is used by default
+func WithMeterProvider(provider metric.MeterProvider) ClientOption {
+	return func(c *GRPCClient) {
+		c.telemetryOptions.meterProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator injecting the trace context into the ingest request metadata, the global one is
+// used by default
+func WithPropagator(propagator propagation.TextMapPropagator) ClientOption {
+	return func(c *GRPCClient) {
+		c.telemetryOptions.propagator = propagator
+	}
+}
+
+// telemetry records the spans and metrics of ingest requests
+type telemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	requests     metric.Int64Counter
+	errors       metric.Int64Counter
+	entities     metric.Int64Counter
+	requestBytes metric.Int64Histogram
+	duration     metric.Float64Histogram
+}
+
+// newTelemetry creates the tracer and metric instruments from the configured or global providers
+func newTelemetry(o telemetryOptions) (*telemetry, error) {
+	if o.tracerProvider == nil {
+		o.tracerProvider = otel.GetTracerProvider()
+	}
+	if o.meterProvider == nil {
+		o.meterProvider = otel.GetMeterProvider()
+	}
+	if o.propagator == nil {
+		o.propagator = otel.GetTextMapPropagator()
+	}
+
+	meter := o.meterProvider.Meter(instrumentationName, metric.WithInstrumentationVersion(SDKVersion))
+
+	t := &telemetry{
+		tracer:     o.tracerProvider.Tracer(instrumentationName, trace.WithInstrumentationVersion(SDKVersion)),
+		propagator: o.propagator,
+	}
+
+	var err error
+	if t.requests, err = meter.Int64Counter("diode.client.requests",
+		metric.WithDescription("Number of ingest requests sent, by gRPC status code"),
+		metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if t.errors, err = meter.Int64Counter("diode.client.errors",
+		metric.WithDescription("Number of failed ingest requests, by gRPC status code"),
+		metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if t.entities, err = meter.Int64Counter("diode.client.entities",
+		metric.WithDescription("Number of entities sent in successful ingest requests, by entity type"),
+		metric.WithUnit("{entity}")); err != nil {
+		return nil, err
+	}
+	if t.requestBytes, err = meter.Int64Histogram("diode.client.request.size",
+		metric.WithDescription("Marshalled size of the ingest requests"),
+		metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	if t.duration, err = meter.Float64Histogram("diode.client.duration",
+		metric.WithDescription("Duration of the ingest requests, including retries"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// spanName returns the name of the span of an ingest RPC, e.g. diode.v1.IngesterService/Ingest
+func spanName(method string) string {
+	return diodepb.IngesterService_ServiceDesc.ServiceName + "/" + method
+}
+
+// start starts the span of an ingest RPC sending the requests and injects its trace context into the outgoing
+// metadata, the request ID and stream of the span are the ones of the first request
+func (t *telemetry) start(ctx context.Context, method string, reqs []*diodepb.IngestRequest) (context.Context, trace.Span) {
+	counts := entityTypeCounts(reqs)
+
+	var first *diodepb.IngestRequest
+	if len(reqs) > 0 {
+		first = reqs[0]
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.RPCSystemGRPC,
+		semconv.RPCService(diodepb.IngesterService_ServiceDesc.ServiceName),
+		semconv.RPCMethod(method),
+		attrRequestID.String(first.GetId()),
+		attrStream.String(first.GetStream()),
+		attrRequestCount.Int(len(reqs)),
+		attrEntityCount.Int(entityCount(reqs)),
+	}
+	for entityType, n := range counts {
+		attrs = append(attrs, attribute.Int(attrEntityTypeCountPrefix+entityType, n))
+	}
+
+	ctx, span := t.tracer.Start(ctx, spanName(method), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	t.propagator.Inject(ctx, metadataCarrier(md))
+
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// end ends the span of an ingest RPC and records the metrics of its requests
+func (t *telemetry) end(ctx context.Context, span trace.Span, reqs []*diodepb.IngestRequest, start time.Time, resp *diodepb.IngestResponse, err error) {
+	code := status.Code(err)
+	codeAttr := semconv.RPCGRPCStatusCodeKey.Int(int(code))
+
+	for _, req := range reqs {
+		streamAttr := attrStream.String(req.GetStream())
+		t.requests.Add(ctx, 1, metric.WithAttributes(streamAttr, codeAttr))
+		t.requestBytes.Record(ctx, int64(proto.Size(req)), metric.WithAttributes(streamAttr))
+		if err != nil {
+			t.errors.Add(ctx, 1, metric.WithAttributes(streamAttr, codeAttr))
+			continue
+		}
+
+		for entityType, n := range entityTypeCounts([]*diodepb.IngestRequest{req}) {
+			t.entities.Add(ctx, int64(n), metric.WithAttributes(streamAttr, attrEntityType.String(entityType)))
+		}
+	}
+
+	if len(reqs) > 0 {
+		streamAttr := attrStream.String(reqs[0].GetStream())
+		t.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(streamAttr, codeAttr))
+	}
+
+	span.SetAttributes(codeAttr)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	} else if n := len(resp.GetErrors()); n > 0 {
+		span.SetAttributes(attribute.Int("diode.response_error_count", n))
+	}
+
+	span.End()
+}
+
+// retried records a retry of an ingest request on its span
+func (t *telemetry) retried(span trace.Span, attempt int, backoff time.Duration, err error) {
+	span.AddEvent("retry", trace.WithAttributes(
+		attribute.Int("diode.attempt", attempt),
+		attribute.Int64("diode.backoff_ms", backoff.Milliseconds()),
+		attribute.String("exception.message", err.Error()),
+	))
+}
+
+// entityTypeCounts returns the number of entities of the requests by entity type, e.g. device or ip_address
+func entityTypeCounts(reqs []*diodepb.IngestRequest) map[string]int {
+	counts := make(map[string]int)
+	for _, req := range reqs {
+		for _, entity := range req.GetEntities() {
+			counts[entityTypeName(entity)]++
+		}
+	}
+	return counts
+}
+
+// entityCount returns the number of entities of the requests
+func entityCount(reqs []*diodepb.IngestRequest) int {
+	n := 0
+	for _, req := range reqs {
+		n += len(req.GetEntities())
+	}
+	return n
+}
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry propagation carrier
+type metadataCarrier metadata.MD
+
+// Get returns the first value of the key
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set sets the value of the key
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys returns the keys of the metadata
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
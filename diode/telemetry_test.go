@@ -0,0 +1,161 @@
+package diode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+func TestIngestTelemetry(t *testing.T) {
+	tests := []struct {
+		desc        string
+		responses   []diodetest.Response
+		retryPolicy RetryPolicy
+		wantErr     bool
+		wantCode    codes.Code
+		wantRetries int
+	}{
+		{
+			desc:     "successful request",
+			wantCode: codes.OK,
+		},
+		{
+			desc:        "successful request after retry",
+			responses:   []diodetest.Response{{Err: status.Error(codes.Unavailable, "unavailable")}},
+			retryPolicy: RetryPolicy{MaxAttempts: 2, RetryableCodes: []codes.Code{codes.Unavailable}},
+			wantCode:    codes.OK,
+			wantRetries: 1,
+		},
+		{
+			desc:      "failed request",
+			responses: []diodetest.Response{{Err: status.Error(codes.Unavailable, "unavailable")}},
+			wantErr:   true,
+			wantCode:  codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t, diodetest.WithResponses(tt.responses...))
+
+			spans := tracetest.NewSpanRecorder()
+			reader := sdkmetric.NewManualReader()
+
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0",
+				WithAPIKey("abcde"),
+				WithRetryPolicy(tt.retryPolicy),
+				WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+				WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+				WithPropagator(propagation.TraceContext{}),
+			)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			requestID := "6b7e7e0e-8f0c-4b5e-9f8e-0a4c0f7a2d11"
+			entities := []Entity{
+				&Site{Name: String("site-1")},
+				&Site{Name: String("site-2")},
+				&Device{Name: String("device-1")},
+			}
+			_, err = client.Ingest(context.Background(), entities, WithRequestID(requestID), WithIngestStream("lab"))
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			ended := spans.Ended()
+			require.Len(t, ended, 1)
+			span := ended[0]
+			assert.Equal(t, "diode.v1.IngesterService/Ingest", span.Name())
+
+			attrs := attribute.NewSet(span.Attributes()...)
+			for key, want := range map[attribute.Key]attribute.Value{
+				attrRequestID:                        attribute.StringValue(requestID),
+				attrStream:                           attribute.StringValue("lab"),
+				attrEntityCount:                      attribute.IntValue(3),
+				attrEntityTypeCountPrefix + "site":   attribute.IntValue(2),
+				attrEntityTypeCountPrefix + "device": attribute.IntValue(1),
+				"rpc.grpc.status_code":               attribute.IntValue(int(tt.wantCode)),
+			} {
+				got, ok := attrs.Value(key)
+				require.True(t, ok, key)
+				assert.Equal(t, want, got, key)
+			}
+
+			retries := 0
+			for _, event := range span.Events() {
+				if event.Name == "retry" {
+					retries++
+				}
+			}
+			assert.Equal(t, tt.wantRetries, retries)
+
+			if tt.wantErr {
+				assert.Equal(t, otelcodes.Error, span.Status().Code)
+			} else {
+				assert.Equal(t, otelcodes.Unset, span.Status().Code)
+			}
+
+			calls := server.Calls()
+			require.Len(t, calls, tt.wantRetries+1)
+			for _, call := range calls {
+				traceparent := call.Metadata.Get("traceparent")
+				require.Len(t, traceparent, 1)
+				assert.Contains(t, traceparent[0], span.SpanContext().TraceID().String())
+			}
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(context.Background(), &rm))
+			metrics := make(map[string]metricdata.Aggregation)
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					metrics[m.Name] = m.Data
+				}
+			}
+
+			requests := metrics["diode.client.requests"].(metricdata.Sum[int64])
+			require.Len(t, requests.DataPoints, 1)
+			assert.Equal(t, int64(1), requests.DataPoints[0].Value)
+			code, _ := requests.DataPoints[0].Attributes.Value("rpc.grpc.status_code")
+			assert.Equal(t, int64(tt.wantCode), code.AsInt64())
+
+			if tt.wantErr {
+				assert.NotContains(t, metrics, "diode.client.entities")
+			} else {
+				entityCounts := make(map[string]int64)
+				for _, dp := range metrics["diode.client.entities"].(metricdata.Sum[int64]).DataPoints {
+					entityType, _ := dp.Attributes.Value(attrEntityType)
+					entityCounts[entityType.AsString()] = dp.Value
+				}
+				assert.Equal(t, map[string]int64{"site": 2, "device": 1}, entityCounts)
+			}
+
+			assert.Equal(t, uint64(1), metrics["diode.client.request.size"].(metricdata.Histogram[int64]).DataPoints[0].Count)
+			assert.Equal(t, uint64(1), metrics["diode.client.duration"].(metricdata.Histogram[float64]).DataPoints[0].Count)
+
+			if tt.wantErr {
+				errs := metrics["diode.client.errors"].(metricdata.Sum[int64])
+				require.Len(t, errs.DataPoints, 1)
+				assert.Equal(t, int64(1), errs.DataPoints[0].Value)
+			} else {
+				assert.NotContains(t, metrics, "diode.client.errors")
+			}
+		})
+	}
+}
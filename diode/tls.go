@@ -0,0 +1,231 @@
+package diode
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsOptions holds the TLS settings collected by the client options
+type tlsOptions struct {
+	// Path of a PEM file with the CA certificates to trust instead of the system ones
+	caCertFile string
+
+	// CA certificates to trust instead of the system ones
+	caCertPool *x509.CertPool
+
+	// Paths of the PEM files with the client certificate and key for mutual TLS
+	clientCertFile string
+	clientKeyFile  string
+
+	// Server name to verify instead of the target host
+	serverName string
+
+	// Minimum TLS version
+	minVersion uint16
+
+	// Whether the server certificate is not verified
+	insecureSkipVerify bool
+}
+
+// WithCACertFile sets a PEM file with the CA certificates used to verify the server instead of the system ones
+func WithCACertFile(path string) ClientOption {
+	return func(c *GRPCClient) {
+		c.tlsOptions.caCertFile = path
+	}
+}
+
+// WithCACertPool sets the CA certificates used to verify the server instead of the system ones
+func WithCACertPool(pool *x509.CertPool) ClientOption {
+	return func(c *GRPCClient) {
+		c.tlsOptions.caCertPool = pool
+	}
+}
+
+// WithClientCertificate sets the PEM files with the client certificate and key presented for mutual TLS
+//
+// The files are reloaded on TLS handshakes once they change, so that rotated certificates are picked up without
+// restarting the client.
+func WithClientCertificate(certFile string, keyFile string) ClientOption {
+	return func(c *GRPCClient) {
+		c.tlsOptions.clientCertFile = certFile
+		c.tlsOptions.clientKeyFile = keyFile
+	}
+}
+
+// WithServerName sets the server name used to verify the server certificate instead of the target host
+func WithServerName(name string) ClientOption {
+	return func(c *GRPCClient) {
+		c.tlsOptions.serverName = name
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version, e.g. tls.VersionTLS13
+func WithMinTLSVersion(version uint16) ClientOption {
+	return func(c *GRPCClient) {
+		c.tlsOptions.minVersion = version
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server certificate, meant for lab environments only
+func WithInsecureSkipVerify() ClientOption {
+	return func(c *GRPCClient) {
+		c.tlsOptions.insecureSkipVerify = true
+	}
+}
+
+// configured reports whether any TLS option has been set
+func (o tlsOptions) configured() bool {
+	return o != (tlsOptions{})
+}
+
+// config builds the TLS configuration for secure channels
+func (o tlsOptions) config(logger *slog.Logger) (*tls.Config, error) {
+	if o.caCertFile != "" && o.caCertPool != nil {
+		return nil, errors.New("CA certificate file and pool are mutually exclusive")
+	}
+
+	if (o.clientCertFile == "") != (o.clientKeyFile == "") {
+		return nil, errors.New("client certificate and key files are both required")
+	}
+
+	cfg := &tls.Config{
+		RootCAs:            o.caCertPool,
+		ServerName:         o.serverName,
+		MinVersion:         o.minVersion,
+		InsecureSkipVerify: o.insecureSkipVerify,
+	}
+
+	if o.caCertFile != "" {
+		pool, err := loadCACertFile(o.caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	if cfg.RootCAs == nil {
+		cfg.RootCAs = loadCerts()
+	}
+
+	if o.clientCertFile != "" {
+		reloader, err := newCertReloader(o.clientCertFile, o.clientKeyFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	if o.insecureSkipVerify {
+		logger.Warn("TLS server certificate verification is disabled")
+	}
+
+	return cfg, nil
+}
+
+// loadCACertFile loads the CA certificates of a PEM file into a new cert pool
+func loadCACertFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid CA certificates found in %s", path)
+	}
+
+	return pool, nil
+}
+
+// certReloader provides a client certificate reloaded when its files change
+type certReloader struct {
+	// The logger for the reloader
+	logger *slog.Logger
+
+	// Paths of the certificate and key PEM files
+	certFile string
+	keyFile  string
+
+	// Guards the fields below
+	mu sync.Mutex
+
+	// Currently loaded certificate
+	cert *tls.Certificate
+
+	// Modification times of the files the certificate was loaded from
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+// newCertReloader creates a reloader and loads the certificate
+func newCertReloader(certFile string, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{
+		logger:   logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetClientCertificate returns the client certificate, reloading it first if its files changed
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.changed() {
+		if err := r.reload(); err != nil {
+			r.logger.Warn("Failed to reload client certificate, keeping the previous one", logAttrError, err)
+		} else {
+			r.logger.Info("Reloaded client certificate", logAttrCertFile, r.certFile)
+		}
+	}
+
+	return r.cert, nil
+}
+
+// changed reports whether the certificate or key file has been modified since the last load
+func (r *certReloader) changed() bool {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false
+	}
+	return !certInfo.ModTime().Equal(r.certModTime) || !keyInfo.ModTime().Equal(r.keyModTime)
+}
+
+// reload loads the certificate and key files
+func (r *certReloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return fmt.Errorf("failed to read client certificate file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read client key file: %w", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+
+	return nil
+}
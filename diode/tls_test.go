@@ -0,0 +1,289 @@
+package diode
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (c testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return cert
+}
+
+func (c testCert) writeFiles(t *testing.T, dir string, name string) (string, string) {
+	t.Helper()
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, c.certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, c.keyPEM, 0o600))
+	return certFile, keyFile
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func newTestCA(t *testing.T) testCert {
+	return newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+}
+
+func newTestLeafCert(t *testing.T, ca testCert, commonName string, usage x509.ExtKeyUsage, dnsNames ...string) testCert {
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	return newTestCert(t, &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}, &ca)
+}
+
+func startTLSMockServer(t *testing.T, serverCert tls.Certificate, clientCAs *x509.CertPool) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MaxVersion:   tls.VersionTLS12,
+	})
+	server := grpc.NewServer(grpc.Creds(creds))
+	diodepb.RegisterIngesterServiceServer(server, &MockIngesterServiceServer{})
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return fmt.Sprintf("grpcs://%s", listener.Addr().String())
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+	caPool := x509.NewCertPool()
+	caPool.AddCert(ca.cert)
+
+	serverCert := newTestLeafCert(t, ca, "diode", x509.ExtKeyUsageServerAuth, "diode.internal")
+	clientCert := newTestLeafCert(t, ca, "agent", x509.ExtKeyUsageClientAuth)
+	clientCertFile, clientKeyFile := clientCert.writeFiles(t, dir, "client")
+
+	target := startTLSMockServer(t, serverCert.tlsCertificate(t), caPool)
+
+	tests := []struct {
+		desc    string
+		opts    []ClientOption
+		wantErr bool
+	}{
+		{
+			desc:    "CA file and client certificate",
+			opts:    []ClientOption{WithCACertFile(caFile), WithClientCertificate(clientCertFile, clientKeyFile), WithServerName("diode.internal")},
+			wantErr: false,
+		},
+		{
+			desc:    "CA pool and client certificate",
+			opts:    []ClientOption{WithCACertPool(caPool), WithClientCertificate(clientCertFile, clientKeyFile), WithServerName("diode.internal")},
+			wantErr: false,
+		},
+		{
+			desc:    "insecure skip verify",
+			opts:    []ClientOption{WithInsecureSkipVerify(), WithClientCertificate(clientCertFile, clientKeyFile)},
+			wantErr: false,
+		},
+		{
+			desc:    "server name mismatch",
+			opts:    []ClientOption{WithCACertFile(caFile), WithClientCertificate(clientCertFile, clientKeyFile)},
+			wantErr: true,
+		},
+		{
+			desc:    "missing client certificate",
+			opts:    []ClientOption{WithCACertFile(caFile), WithServerName("diode.internal")},
+			wantErr: true,
+		},
+		{
+			desc:    "system CA certificates",
+			opts:    []ClientOption{WithClientCertificate(clientCertFile, clientKeyFile), WithServerName("diode.internal")},
+			wantErr: true,
+		},
+		{
+			desc:    "minimum TLS version not supported by server",
+			opts:    []ClientOption{WithCACertFile(caFile), WithClientCertificate(clientCertFile, clientKeyFile), WithServerName("diode.internal"), WithMinTLSVersion(tls.VersionTLS13)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			opts := append([]ClientOption{WithAPIKey("abcde")}, tt.opts...)
+			client, err := NewClient(target, "my-producer", "0.1.0", opts...)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = client.Ingest(ctx, []Entity{&Site{Name: String("site-1")}})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewClientTLSOptionErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	invalidCAFile := filepath.Join(dir, "invalid.crt")
+	require.NoError(t, os.WriteFile(invalidCAFile, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		desc    string
+		target  string
+		opts    []ClientOption
+		wantErr error
+	}{
+		{
+			desc:    "TLS options with insecure target",
+			target:  "grpc://localhost:8081",
+			opts:    []ClientOption{WithServerName("diode.internal")},
+			wantErr: errors.New("TLS options require a grpcs:// target"),
+		},
+		{
+			desc:    "invalid CA certificate file",
+			target:  "grpcs://localhost:8081",
+			opts:    []ClientOption{WithCACertFile(invalidCAFile)},
+			wantErr: fmt.Errorf("no valid CA certificates found in %s", invalidCAFile),
+		},
+		{
+			desc:    "CA certificate file and pool",
+			target:  "grpcs://localhost:8081",
+			opts:    []ClientOption{WithCACertFile(invalidCAFile), WithCACertPool(x509.NewCertPool())},
+			wantErr: errors.New("CA certificate file and pool are mutually exclusive"),
+		},
+		{
+			desc:    "client certificate without key",
+			target:  "grpcs://localhost:8081",
+			opts:    []ClientOption{WithClientCertificate(filepath.Join(dir, "client.crt"), "")},
+			wantErr: errors.New("client certificate and key files are both required"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			opts := append([]ClientOption{WithAPIKey("abcde")}, tt.opts...)
+			client, err := NewClient(tt.target, "my-producer", "0.1.0", opts...)
+			require.Nil(t, client)
+			require.EqualError(t, err, tt.wantErr.Error())
+		})
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+
+	first := newTestLeafCert(t, ca, "agent-1", x509.ExtKeyUsageClientAuth)
+	certFile, keyFile := first.writeFiles(t, dir, "client")
+
+	reloader, err := newCertReloader(certFile, keyFile, newLogger(io.Discard, LogFormatJSON))
+	require.NoError(t, err)
+
+	cert, err := reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, cert.Certificate[0])
+
+	second := newTestLeafCert(t, ca, "agent-2", x509.ExtKeyUsageClientAuth)
+	second.writeFiles(t, dir, "client")
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	cert, err = reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, cert.Certificate[0])
+
+	// an unreadable rotation keeps the previous certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	modTime = modTime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+
+	cert, err = reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, cert.Certificate[0])
+}
@@ -0,0 +1,135 @@
+// Stubs of the client-streaming IngestStream RPC of IngesterService:
+//
+//	rpc IngestStream(stream IngestRequest) returns (IngestResponse) {}
+//
+// They follow the code protoc-gen-go-grpc generates for client-streaming RPCs and are kept apart from the generated
+// files until diode/v1/ingester.proto declares the RPC, servers without it reply with codes.Unimplemented.
+
+package diodepb
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+const (
+	IngesterService_IngestStream_FullMethodName = "/diode.v1.IngesterService/IngestStream"
+)
+
+// IngesterServiceStreamClient is the client API for the IngestStream RPC of IngesterService service.
+type IngesterServiceStreamClient interface {
+	// Ingests data sent as a stream of requests into the system
+	IngestStream(ctx context.Context, opts ...grpc.CallOption) (IngesterService_IngestStreamClient, error)
+}
+
+type ingesterServiceStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIngesterServiceStreamClient(cc grpc.ClientConnInterface) IngesterServiceStreamClient {
+	return &ingesterServiceStreamClient{cc}
+}
+
+func (c *ingesterServiceStreamClient) IngestStream(ctx context.Context, opts ...grpc.CallOption) (IngesterService_IngestStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &IngesterServiceStream_ServiceDesc.Streams[0], IngesterService_IngestStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterServiceIngestStreamClient{stream}
+	return x, nil
+}
+
+type IngesterService_IngestStreamClient interface {
+	Send(*IngestRequest) error
+	CloseAndRecv() (*IngestResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterServiceIngestStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterServiceIngestStreamClient) Send(m *IngestRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingesterServiceIngestStreamClient) CloseAndRecv() (*IngestResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(IngestResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngesterServiceStreamServer is the server API for IngesterService service including the IngestStream RPC.
+// All implementations must embed UnimplementedIngesterServiceStreamServer
+// for forward compatibility
+type IngesterServiceStreamServer interface {
+	IngesterServiceServer
+	// Ingests data sent as a stream of requests into the system
+	IngestStream(IngesterService_IngestStreamServer) error
+}
+
+// UnimplementedIngesterServiceStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedIngesterServiceStreamServer struct {
+	UnimplementedIngesterServiceServer
+}
+
+func (UnimplementedIngesterServiceStreamServer) IngestStream(IngesterService_IngestStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method IngestStream not implemented")
+}
+
+// RegisterIngesterServiceStreamServer registers the IngesterService service including the IngestStream RPC, it
+// replaces RegisterIngesterServiceServer.
+func RegisterIngesterServiceStreamServer(s grpc.ServiceRegistrar, srv IngesterServiceStreamServer) {
+	s.RegisterService(&IngesterServiceStream_ServiceDesc, srv)
+}
+
+func _IngesterService_IngestStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterServiceStreamServer).IngestStream(&ingesterServiceIngestStreamServer{stream})
+}
+
+type IngesterService_IngestStreamServer interface {
+	SendAndClose(*IngestResponse) error
+	Recv() (*IngestRequest, error)
+	grpc.ServerStream
+}
+
+type ingesterServiceIngestStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterServiceIngestStreamServer) SendAndClose(m *IngestResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterServiceIngestStreamServer) Recv() (*IngestRequest, error) {
+	m := new(IngestRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngesterServiceStream_ServiceDesc is the grpc.ServiceDesc for IngesterService service including the IngestStream
+// RPC. It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IngesterServiceStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: IngesterService_ServiceDesc.ServiceName,
+	HandlerType: (*IngesterServiceStreamServer)(nil),
+	Methods:     IngesterService_ServiceDesc.Methods,
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestStream",
+			Handler:       _IngesterService_IngestStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: IngesterService_ServiceDesc.Metadata,
+}
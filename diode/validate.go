@@ -0,0 +1,200 @@
+package diode
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+// ValidationMode defines how the client validates entities before sending them
+type ValidationMode int
+
+const (
+	// ValidationOff sends entities without validating them, leaving validation to the ingester service
+	ValidationOff ValidationMode = iota
+
+	// ValidationFailFast rejects the whole ingest at the first invalid entity without sending anything
+	ValidationFailFast
+
+	// ValidationSkipInvalid sends the valid entities only and reports the invalid ones in IngestResponse.Errors, see
+	// WithSkippedEntities
+	ValidationSkipInvalid
+)
+
+// String returns the name of the validation mode
+func (m ValidationMode) String() string {
+	switch m {
+	case ValidationOff:
+		return "off"
+	case ValidationFailFast:
+		return "fail-fast"
+	case ValidationSkipInvalid:
+		return "skip-invalid"
+	default:
+		return fmt.Sprintf("ValidationMode(%d)", int(m))
+	}
+}
+
+// WithValidation sets how entities are validated against the ingester service rules before sending them, entities
+// are not validated by default
+func WithValidation(mode ValidationMode) ClientOption {
+	return func(c *GRPCClient) {
+		c.validationMode = mode
+	}
+}
+
+// WithSkippedEntities sets errs to the validation errors of the entities skipped by an Ingest call with
+// ValidationSkipInvalid, nil if none was skipped, as the IngestResponse.Errors only hold their messages
+//
+// The errors are set by each ingest request, the option must not be shared by concurrent calls, e.g. through a Batcher
+// or a Producer.
+func WithSkippedEntities(errs *ValidationErrors) IngestOption {
+	return func(o *ingestOptions) {
+		o.skipped = errs
+	}
+}
+
+// ValidationError is a validation rule violation of a field of an entity
+type ValidationError struct {
+	// Index is the index of the entity in the ingested entities
+	Index int
+
+	// Field is the path of the field within the entity, e.g. Device.DeviceType.Model
+	Field string
+
+	// Reason describes the violated rule
+	Reason string
+}
+
+// Error returns the validation error message
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("entity %d: invalid %s: %s", e.Index, e.Field, e.Reason)
+}
+
+// ValidationErrors holds all validation rule violations of the ingested entities
+type ValidationErrors []*ValidationError
+
+// Error returns the validation error messages, one per line
+func (e ValidationErrors) Error() string {
+	return strings.Join(e.messages(), "\n")
+}
+
+// Unwrap returns the individual validation errors
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Indexes returns the sorted indexes of the invalid entities
+func (e ValidationErrors) Indexes() []int {
+	var indexes []int
+	for _, err := range e {
+		if !slices.Contains(indexes, err.Index) {
+			indexes = append(indexes, err.Index)
+		}
+	}
+	slices.Sort(indexes)
+	return indexes
+}
+
+// messages returns the validation error messages
+func (e ValidationErrors) messages() []string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return msgs
+}
+
+// Validate validates the entities against the ingester service rules, returning ValidationErrors if any is invalid
+//
+// Entities without a discovery timestamp are validated as if stamped with the time of the call, as the client does
+// by default.
+func Validate(entities []Entity) error {
+	now := timestamppb.New(time.Now())
+
+	protoEntities := make([]*diodepb.Entity, 0, len(entities))
+	for _, entity := range entities {
+		protoEntity := entity.ConvertToProtoEntity()
+		if protoEntity.Timestamp == nil {
+			protoEntity.Timestamp = now
+		}
+		protoEntities = append(protoEntities, protoEntity)
+	}
+
+	if errs := validateProtoEntities(protoEntities, false); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateProtoEntities validates the entities, stopping at the first invalid entity if failFast is set
+func validateProtoEntities(entities []*diodepb.Entity, failFast bool) ValidationErrors {
+	var errs ValidationErrors
+	for i, entity := range entities {
+		if err := entity.ValidateAll(); err != nil {
+			errs = append(errs, flattenValidationError(i, "", err)...)
+			if failFast {
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// skipEntities returns the entities without the ones at the given sorted indexes
+func skipEntities(entities []*diodepb.Entity, indexes []int) []*diodepb.Entity {
+	kept := make([]*diodepb.Entity, 0, len(entities))
+	for i, entity := range entities {
+		if _, found := slices.BinarySearch(indexes, i); !found {
+			kept = append(kept, entity)
+		}
+	}
+	return kept
+}
+
+// fieldValidationError is implemented by the validation errors generated by protoc-gen-validate
+type fieldValidationError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiValidationError is implemented by the multi-errors generated by protoc-gen-validate
+type multiValidationError interface {
+	AllErrors() []error
+}
+
+// flattenValidationError converts a possibly nested protoc-gen-validate error into validation errors with field paths
+func flattenValidationError(index int, path string, err error) ValidationErrors {
+	switch e := err.(type) {
+	case multiValidationError:
+		var errs ValidationErrors
+		for _, nested := range e.AllErrors() {
+			errs = append(errs, flattenValidationError(index, path, nested)...)
+		}
+		return errs
+	case fieldValidationError:
+		fieldPath := e.Field()
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		switch e.Cause().(type) {
+		case multiValidationError, fieldValidationError:
+			return flattenValidationError(index, fieldPath, e.Cause())
+		}
+
+		return ValidationErrors{{Index: index, Field: fieldPath, Reason: e.Reason()}}
+	default:
+		return ValidationErrors{{Index: index, Field: path, Reason: err.Error()}}
+	}
+}
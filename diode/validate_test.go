@@ -0,0 +1,185 @@
+package diode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/diodetest"
+)
+
+func validSite(name string) *Site {
+	return &Site{Name: String(name), Slug: String(name), Status: String("active")}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		desc     string
+		entities []Entity
+		wantErr  ValidationErrors
+	}{
+		{
+			desc:     "valid entities",
+			entities: []Entity{validSite("site-1"), validSite("site-2")},
+			wantErr:  nil,
+		},
+		{
+			desc:     "ip address without assigned object",
+			entities: []Entity{&IPAddress{Address: String("192.168.0.1"), Status: String("active"), Role: String("vip")}},
+			wantErr:  nil,
+		},
+		{
+			desc: "invalid nested field",
+			entities: []Entity{
+				validSite("site-1"),
+				&Device{
+					Name:   String("device-1"),
+					Status: String("active"),
+					Site:   &Site{Name: String("site 2"), Slug: String("site 2"), Status: String("active")},
+				},
+			},
+			wantErr: ValidationErrors{
+				{Index: 1, Field: "Device.Site.Slug", Reason: `value does not match regex pattern "^[-a-zA-Z0-9_]+$"`},
+			},
+		},
+		{
+			desc: "multiple invalid fields",
+			entities: []Entity{
+				&Device{Name: String(strings.Repeat("a", 65)), Status: String("unknown")},
+				validSite("site-1"),
+			},
+			wantErr: ValidationErrors{
+				{Index: 0, Field: "Device.Name", Reason: "value length must be at most 64 runes"},
+				{Index: 0, Field: "Device.Status", Reason: "value must be in list [offline active planned staged failed inventory decommissioning]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := Validate(tt.entities)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var errs ValidationErrors
+			require.True(t, errors.As(err, &errs))
+			assert.Equal(t, tt.wantErr, errs)
+		})
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	errs := ValidationErrors{
+		{Index: 3, Field: "Site.Slug", Reason: "value is required"},
+		{Index: 1, Field: "Device.Name", Reason: "value is too long"},
+		{Index: 3, Field: "Site.Status", Reason: "value is required"},
+	}
+
+	assert.Equal(t, []int{1, 3}, errs.Indexes())
+	assert.Equal(t, "entity 3: invalid Site.Slug: value is required\n"+
+		"entity 1: invalid Device.Name: value is too long\n"+
+		"entity 3: invalid Site.Status: value is required", errs.Error())
+
+	var target *ValidationError
+	require.True(t, errors.As(error(errs), &target))
+	assert.Equal(t, 3, target.Index)
+}
+
+func TestIngestValidation(t *testing.T) {
+	invalid := &Site{Name: String("site 2"), Slug: String("site-2")}
+
+	tests := []struct {
+		desc         string
+		mode         ValidationMode
+		entities     []Entity
+		wantRequests int
+		wantSent     []string
+		wantErrors   []string
+		wantSkipped  ValidationErrors
+		wantErr      error
+	}{
+		{
+			desc:         "validation off",
+			mode:         ValidationOff,
+			entities:     []Entity{validSite("site-1"), invalid},
+			wantRequests: 1,
+			wantSent:     []string{"site-1", "site 2"},
+		},
+		{
+			desc:     "fail fast",
+			mode:     ValidationFailFast,
+			entities: []Entity{validSite("site-1"), invalid},
+			wantErr: ValidationErrors{
+				{Index: 1, Field: "Site.Status", Reason: "value must be in list [planned staging active decommissioning retired]"},
+			},
+		},
+		{
+			desc:         "skip invalid",
+			mode:         ValidationSkipInvalid,
+			entities:     []Entity{validSite("site-1"), invalid},
+			wantRequests: 1,
+			wantSent:     []string{"site-1"},
+			wantErrors:   []string{"entity 1: invalid Site.Status: value must be in list [planned staging active decommissioning retired]"},
+			wantSkipped: ValidationErrors{
+				{Index: 1, Field: "Site.Status", Reason: "value must be in list [planned staging active decommissioning retired]"},
+			},
+		},
+		{
+			desc:       "skip invalid without valid entities",
+			mode:       ValidationSkipInvalid,
+			entities:   []Entity{invalid},
+			wantErrors: []string{"entity 0: invalid Site.Status: value must be in list [planned staging active decommissioning retired]"},
+			wantSkipped: ValidationErrors{
+				{Index: 0, Field: "Site.Status", Reason: "value must be in list [planned staging active decommissioning retired]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := diodetest.Start(t)
+
+			client, err := NewClient(server.Target(), "my-producer", "0.1.0", WithAPIKey("abcde"), WithValidation(tt.mode))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, client.Close())
+			}()
+
+			var skipped ValidationErrors
+			resp, err := client.Ingest(context.Background(), tt.entities, WithSkippedEntities(&skipped))
+			assert.Equal(t, tt.wantSkipped, skipped)
+			if tt.wantErr != nil {
+				require.Equal(t, tt.wantErr, err)
+				require.Empty(t, server.Requests())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantErrors, resp.GetErrors())
+
+			requests := server.Requests()
+			require.Len(t, requests, tt.wantRequests)
+			if tt.wantRequests == 0 {
+				return
+			}
+
+			var sent []string
+			for _, e := range requests[0].GetEntities() {
+				sent = append(sent, e.GetSite().GetName())
+			}
+			assert.Equal(t, tt.wantSent, sent)
+		})
+	}
+}
+
+func TestValidationModeString(t *testing.T) {
+	assert.Equal(t, "off", ValidationOff.String())
+	assert.Equal(t, "fail-fast", ValidationFailFast.String())
+	assert.Equal(t, "skip-invalid", ValidationSkipInvalid.String())
+	assert.Equal(t, "ValidationMode(7)", ValidationMode(7).String())
+}
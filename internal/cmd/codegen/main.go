@@ -1,9 +1,30 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
 	"github.com/netboxlabs/diode-sdk-go/internal/codegen"
 )
 
 func main() {
-	codegen.GenerateDiodeStructs()
+	out := flag.String("out", "diode", "directory of the generated files")
+	flag.Parse()
+
+	files, err := codegen.Generate(codegen.Config{
+		Package: "diode",
+		Entity:  (*diodepb.Entity)(nil).ProtoReflect().Descriptor(),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(*out, name), src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,372 @@
+package codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/netboxlabs/diode-sdk-go/diode/v1/diodepb"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// fieldProto returns a field descriptor proto
+func fieldProto(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, opts ...func(*descriptorpb.FieldDescriptorProto)) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(jsonName(name)),
+		Number:   proto.Int32(number),
+		Type:     typ.Enum(),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
+}
+
+// typeName sets the type name of a message or enum field
+func typeName(name string) func(*descriptorpb.FieldDescriptorProto) {
+	return func(f *descriptorpb.FieldDescriptorProto) {
+		f.TypeName = proto.String(name)
+	}
+}
+
+// repeated makes a field repeated
+func repeated(f *descriptorpb.FieldDescriptorProto) {
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+}
+
+// inOneof places a field in the oneof at the index, proto3 optional if synthetic
+func inOneof(index int32, synthetic bool) func(*descriptorpb.FieldDescriptorProto) {
+	return func(f *descriptorpb.FieldDescriptorProto) {
+		f.OneofIndex = proto.Int32(index)
+		if synthetic {
+			f.Proto3Optional = proto.Bool(true)
+		}
+	}
+}
+
+// jsonName returns the lower camel case JSON name of a field
+func jsonName(name string) string {
+	return lowerFirst(goCamelCase(name))
+}
+
+// oneofs returns oneof descriptor protos
+func oneofs(names ...string) []*descriptorpb.OneofDescriptorProto {
+	var decls []*descriptorpb.OneofDescriptorProto
+	for _, n := range names {
+		decls = append(decls, &descriptorpb.OneofDescriptorProto{Name: proto.String(n)})
+	}
+	return decls
+}
+
+// testFile returns a proto file with fields of every supported kind, messages are modified by the options
+func testFile(t *testing.T, opts ...func(*descriptorpb.FileDescriptorProto)) protoreflect.FileDescriptor {
+	t.Helper()
+
+	const (
+		str = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		msg = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("example/v1/example.proto"),
+		Package:    proto.String("example.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/example/v1/examplepb")},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Color"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("COLOR_UNSPECIFIED"), Number: proto.Int32(0)},
+				{Name: proto.String("COLOR_RED"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Label"),
+				Field: []*descriptorpb.FieldDescriptorProto{fieldProto("name", 1, str)},
+			},
+			{
+				Name: proto.String("Gadget"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					fieldProto("model", 1, str),
+					fieldProto("widget", 2, msg, typeName(".example.v1.Widget"), inOneof(0, false)),
+				},
+				OneofDecl: oneofs("parent"),
+			},
+			{
+				Name: proto.String("Widget"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					fieldProto("name", 1, str),
+					fieldProto("description", 2, str, inOneof(1, true)),
+					fieldProto("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+					fieldProto("port", 4, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+					fieldProto("serial_number", 5, descriptorpb.FieldDescriptorProto_TYPE_FIXED64),
+					fieldProto("weight", 6, descriptorpb.FieldDescriptorProto_TYPE_FLOAT),
+					fieldProto("ratio", 7, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+					fieldProto("enabled", 8, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+					fieldProto("checksum", 9, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+					fieldProto("color", 10, descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName(".example.v1.Color")),
+					fieldProto("accent", 11, descriptorpb.FieldDescriptorProto_TYPE_ENUM, typeName(".example.v1.Color"), inOneof(2, true)),
+					fieldProto("aliases", 12, str, repeated),
+					fieldProto("ports", 13, descriptorpb.FieldDescriptorProto_TYPE_SINT32, repeated),
+					fieldProto("seen_at", 14, msg, typeName(".google.protobuf.Timestamp")),
+					fieldProto("gadget", 15, msg, typeName(".example.v1.Gadget")),
+					fieldProto("labels", 16, msg, typeName(".example.v1.Label"), repeated),
+					fieldProto("primary", 17, msg, typeName(".example.v1.Gadget"), inOneof(0, false)),
+					fieldProto("secondary", 18, msg, typeName(".example.v1.Label"), inOneof(0, false)),
+					fieldProto("type", 19, descriptorpb.FieldDescriptorProto_TYPE_INT32, inOneof(3, true)),
+				},
+				OneofDecl: oneofs("attachment", "_description", "_accent", "_type"),
+			},
+			{
+				Name: proto.String("Entity"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					fieldProto("widget", 1, msg, typeName(".example.v1.Widget"), inOneof(0, false)),
+					fieldProto("gadget", 2, msg, typeName(".example.v1.Gadget"), inOneof(0, false)),
+					fieldProto("timestamp", 3, msg, typeName(".google.protobuf.Timestamp")),
+				},
+				OneofDecl: oneofs("entity"),
+			},
+		},
+	}
+
+	for _, o := range opts {
+		o(fdp)
+	}
+
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	return fd
+}
+
+// widget returns the Widget message proto of the test file
+func widget(fdp *descriptorpb.FileDescriptorProto) *descriptorpb.DescriptorProto {
+	return fdp.GetMessageType()[2]
+}
+
+func TestGenerateGolden(t *testing.T) {
+	files, err := Generate(Config{
+		Package: "example",
+		Entity:  testFile(t).Messages().ByName("Entity"),
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	for name, src := range files {
+		golden := filepath.Join("testdata", name+".golden")
+		if *update {
+			require.NoError(t, os.WriteFile(golden, src, 0o644))
+			continue
+		}
+
+		want, err := os.ReadFile(golden)
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(src), "%s is out of date, run go test ./internal/codegen -update", golden)
+	}
+}
+
+func TestGenerateDiodeUpToDate(t *testing.T) {
+	files, err := Generate(Config{
+		Package: "diode",
+		Entity:  (*diodepb.Entity)(nil).ProtoReflect().Descriptor(),
+	})
+	require.NoError(t, err)
+
+	for name, src := range files {
+		want, err := os.ReadFile(filepath.Join("..", "..", "diode", name))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(src), "diode/%s is out of date, run make codegen", name)
+	}
+}
+
+func TestGenerateWithoutEntityTimestamp(t *testing.T) {
+	fd := testFile(t, func(fdp *descriptorpb.FileDescriptorProto) {
+		entity := fdp.GetMessageType()[3]
+		entity.Field = entity.Field[:2]
+	})
+
+	files, err := Generate(Config{
+		Package: "example",
+		Entity:  fd.Messages().ByName("Entity"),
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, string(files[IngesterFile]), "TimestampedEntity")
+	assert.NotContains(t, string(files[IngesterFile]), "entityTimestampKey")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		desc    string
+		modify  func(*descriptorpb.FileDescriptorProto)
+		wantErr string
+	}{
+		{
+			desc: "missing go_package",
+			modify: func(fdp *descriptorpb.FileDescriptorProto) {
+				fdp.Options = nil
+			},
+			wantErr: "example/v1/example.proto: go_package option is required",
+		},
+		{
+			desc: "map field",
+			modify: func(fdp *descriptorpb.FileDescriptorProto) {
+				w := widget(fdp)
+				w.NestedType = append(w.NestedType, &descriptorpb.DescriptorProto{
+					Name: proto.String("AttributesEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						fieldProto("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+						fieldProto("value", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				})
+				w.Field = append(w.Field, fieldProto("attributes", 20, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+					typeName(".example.v1.Widget.AttributesEntry"), repeated))
+			},
+			wantErr: "example.v1.Widget.attributes: map fields are not supported",
+		},
+		{
+			desc: "message of another package",
+			modify: func(fdp *descriptorpb.FileDescriptorProto) {
+				fdp.Dependency = append(fdp.Dependency, "google/protobuf/duration.proto")
+				w := widget(fdp)
+				w.Field = append(w.Field, fieldProto("timeout", 20, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+					typeName(".google.protobuf.Duration")))
+			},
+			wantErr: "google.protobuf.Duration: messages of other packages are not supported",
+		},
+		{
+			desc: "scalar oneof member",
+			modify: func(fdp *descriptorpb.FileDescriptorProto) {
+				g := fdp.GetMessageType()[1]
+				g.Field = append(g.Field, fieldProto("serial", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, inOneof(0, false)))
+			},
+			wantErr: "example.v1.Gadget.serial: scalar oneof members are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := Generate(Config{
+				Package: "example",
+				Entity:  testFile(t, tt.modify).Messages().ByName("Entity"),
+			})
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// rules sets the validation rules of a field
+func rules(r *validate.FieldRules) func(*descriptorpb.FieldDescriptorProto) {
+	return func(f *descriptorpb.FieldDescriptorProto) {
+		f.Options = &descriptorpb.FieldOptions{}
+		proto.SetExtension(f.Options, validate.E_Rules, r)
+	}
+}
+
+func TestRefDefaults(t *testing.T) {
+	stringRules := func(s *validate.StringRules) *validate.FieldRules {
+		return &validate.FieldRules{Type: &validate.FieldRules_String_{String_: s}}
+	}
+
+	tests := []struct {
+		desc         string
+		field        *descriptorpb.FieldDescriptorProto
+		wantRequired bool
+		wantDefault  string
+	}{
+		{
+			desc: "slug",
+			field: fieldProto("slug", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+				rules(stringRules(&validate.StringRules{MinLen: proto.Uint64(1)}))),
+			wantRequired: true,
+			wantDefault:  "Slugify(name)",
+		},
+		{
+			desc: "status in list",
+			field: fieldProto("status", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+				rules(stringRules(&validate.StringRules{In: []string{"active", "planned"}}))),
+			wantRequired: true,
+			wantDefault:  `"active"`,
+		},
+		{
+			desc: "status not in list",
+			field: fieldProto("status", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+				rules(stringRules(&validate.StringRules{In: []string{"planned"}}))),
+			wantRequired: true,
+		},
+		{
+			desc: "color pattern",
+			field: fieldProto("color", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+				rules(stringRules(&validate.StringRules{Pattern: proto.String("^[0-9a-f]{6}$")}))),
+			wantRequired: true,
+			wantDefault:  `"9e9e9e"`,
+		},
+		{
+			desc: "color ignoring empty",
+			field: fieldProto("color", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+				rules(stringRules(&validate.StringRules{Pattern: proto.String("^[0-9a-f]{6}$"), IgnoreEmpty: proto.Bool(true)}))),
+			wantRequired: false,
+		},
+		{
+			desc: "no default",
+			field: fieldProto("kind", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING,
+				rules(stringRules(&validate.StringRules{In: []string{"a", "b"}}))),
+			wantRequired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fd := testFile(t, func(fdp *descriptorpb.FileDescriptorProto) {
+				label := fdp.GetMessageType()[0]
+				label.Field = append(label.Field, tt.field)
+			})
+			f, err := newFile("example", fd.Messages().ByName("Entity"))
+			require.NoError(t, err)
+
+			label := f.Messages[slices.IndexFunc(f.Messages, func(m *message) bool { return m.Name == "Label" })]
+			require.Len(t, label.Fields, 2)
+			assert.Equal(t, tt.wantRequired, label.Fields[1].Required)
+			assert.Equal(t, tt.wantDefault, label.Fields[1].Default)
+			if tt.wantDefault != "" {
+				assert.Equal(t, "newLabelRef(v)", label.Ref("v"))
+			} else {
+				assert.Equal(t, "&Label{Name: &v}", label.Ref("v"))
+			}
+		})
+	}
+}
+
+func TestGoCamelCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "name", want: "Name"},
+		{name: "ip_address", want: "IpAddress"},
+		{name: "primary_ip4", want: "PrimaryIp4"},
+		{name: "vminterface", want: "Vminterface"},
+		{name: "IPAddress", want: "IPAddress"},
+		{name: "_private", want: "XPrivate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, goCamelCase(tt.name))
+		})
+	}
+}
@@ -0,0 +1,723 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Kinds of the fields of the entity structs
+const (
+	// kindScalar is a scalar without presence, its getter returns the value
+	kindScalar = "scalar"
+
+	// kindOptionalScalar is a proto3 optional scalar, its getter returns the pointer
+	kindOptionalScalar = "optionalScalar"
+
+	// kindBytes is a bytes field
+	kindBytes = "bytes"
+
+	// kindRepeatedScalar is a repeated scalar or bytes field
+	kindRepeatedScalar = "repeatedScalar"
+
+	// kindTimestamp is a google.protobuf.Timestamp field, a *time.Time in the entity struct
+	kindTimestamp = "timestamp"
+
+	// kindMessage is a message field
+	kindMessage = "message"
+
+	// kindRepeatedMessage is a repeated message field
+	kindRepeatedMessage = "repeatedMessage"
+
+	// kindOneofMessage is the only message of a oneof, the struct field is named after the oneof
+	kindOneofMessage = "oneofMessage"
+
+	// kindOneofMember is a message of a oneof of several messages
+	kindOneofMember = "oneofMember"
+)
+
+// identifyingFieldNames are the names of the fields identifying an entity, in order of preference
+var identifyingFieldNames = []protoreflect.Name{"name", "model", "address", "prefix"}
+
+// refDefaultValues are the values of required fields set by the builders on the messages they reference by their
+// identifying field, as NetBox defaults them, slugs are derived from the identifying field
+var refDefaultValues = map[protoreflect.Name]string{
+	"color":  "9e9e9e",
+	"status": "active",
+}
+
+// timestampName is the full name of the well-known timestamp message
+var timestampName = (*timestamppb.Timestamp)(nil).ProtoReflect().Descriptor().FullName()
+
+// file is the model of the generated files
+type file struct {
+	// Package is the name of the package of the generated code
+	Package string
+
+	// ProtoPackage is the name of the Go package of the proto messages, e.g. diodepb
+	ProtoPackage string
+
+	// ProtoImportPath is the import path of the Go package of the proto messages
+	ProtoImportPath string
+
+	// EntityMessage is the Go name of the message wrapping the entities
+	EntityMessage string
+
+	// EntityOneof is the Go name of the oneof field of the entities in the wrapping message
+	EntityOneof string
+
+	// EntityTimestamp is the discovery timestamp field of the wrapping message, nil if it has none
+	EntityTimestamp *field
+
+	// Messages are the messages of the entities and the messages they reference, sorted by name
+	Messages []*message
+
+	// Entities are the members of the entity oneof, in declaration order
+	Entities []*entity
+}
+
+// message is the model of an entity struct generated from a proto message
+type message struct {
+	// Name is the Go name of the message, shared by the entity struct
+	Name string
+
+	Fields []*field
+
+	// Oneofs are the oneofs of several messages, set by a switch in ConvertToProtoMessage
+	Oneofs []*oneof
+
+	// ID is the field identifying the entities of the message, nil if there is none
+	ID *field
+
+	// Defaults are the required fields set to default values when the message is referenced by its ID
+	Defaults []*field
+
+	// Entity is the member of the entity oneof of the message, nil if it is not an entity
+	Entity *entity
+}
+
+// Ref returns the Go expression of a message referenced by its ID set to the variable, with its defaults
+func (m *message) Ref(param string) string {
+	if len(m.Defaults) > 0 {
+		return fmt.Sprintf("new%sRef(%s)", m.Name, param)
+	}
+	return fmt.Sprintf("&%s{%s: &%s}", m.Name, m.ID.GoName, param)
+}
+
+// SetsOneofs reports whether ConvertToProtoMessage sets oneofs after the other fields, so that unset entity fields leave
+// them unset instead of setting them to nil wrappers
+func (m *message) SetsOneofs() bool {
+	for _, f := range m.Fields {
+		if f.Kind == kindOneofMessage {
+			return true
+		}
+	}
+	return len(m.Oneofs) > 0
+}
+
+// oneof is a oneof of several messages
+type oneof struct {
+	// GoName is the name of the oneof field of the proto message
+	GoName string
+
+	Members []*field
+}
+
+// field is the model of a field of an entity struct
+type field struct {
+	// Owner is the name of the entity struct of the field
+	Owner string
+
+	// GoName is the name of the field in the entity struct
+	GoName string
+
+	// Tag is the JSON and YAML name of the field
+	Tag string
+
+	// Kind is the kind of the field, one of the kind constants
+	Kind string
+
+	// Type is the type of the field in the entity struct
+	Type string
+
+	// ElemType is the type of a scalar value or of the elements of a repeated scalar field
+	ElemType string
+
+	// ProtoType is the type of the field in the proto message, returned by the getter
+	ProtoType string
+
+	// Zero is the zero value of a scalar field
+	Zero string
+
+	// Message is the referenced message of message fields
+	Message *message
+
+	// Oneof is the Go name of the oneof field of the proto message of oneof fields
+	Oneof string
+
+	// MemberGoName is the name of the oneof member field in the proto oneof wrapper and of its proto message getter
+	MemberGoName string
+
+	// OneofWrapper is the Go name of the proto oneof wrapper type of oneof fields
+	OneofWrapper string
+
+	// Required reports whether the validation rules require the field to be set, rejecting its zero value
+	Required bool
+
+	// Param is the name of the builder parameter setting the field
+	Param string
+
+	// Default is the Go expression of the default value of the field when its message is referenced by its ID
+	Default string
+}
+
+// Plural returns the name of the builder parameter setting the field of several entities, e.g. names
+func (f *field) Plural() string {
+	if strings.HasSuffix(f.Param, "s") {
+		return f.Param + "es"
+	}
+	return f.Param + "s"
+}
+
+// VarName returns the name of the local variable holding the converted elements of a repeated message field
+func (f *field) VarName() string {
+	return identifier(strings.ToLower(f.GoName))
+}
+
+// RefsName returns the name of the builder method adding shared elements to a repeated message field, e.g. TagRefs
+func (f *field) RefsName() string {
+	return strings.TrimSuffix(f.GoName, "s") + "Refs"
+}
+
+// RefParam returns the name of the builder parameter setting a message field to a shared message
+func (f *field) RefParam() string {
+	return identifier(lowerFirst(f.GoName))
+}
+
+// entity is a member of the entity oneof
+type entity struct {
+	// TypeName is the proto name of the oneof member, e.g. device_role
+	TypeName string
+
+	// GoName is the name of the member field in the proto oneof wrapper, e.g. DeviceRole
+	GoName string
+
+	// Wrapper is the Go name of the proto oneof wrapper type, e.g. Entity_DeviceRole
+	Wrapper string
+
+	Message *message
+}
+
+// usesKind reports whether any field of the messages is of one of the kinds
+func (f *file) usesKind(kinds ...string) bool {
+	for _, m := range f.Messages {
+		for _, fd := range m.Fields {
+			for _, k := range kinds {
+				if fd.Kind == k {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// UsesTimestamps reports whether any field is a timestamp
+func (f *file) UsesTimestamps() bool {
+	return f.usesKind(kindTimestamp)
+}
+
+// UsesEnums reports whether any scalar field is an enum, typed by the proto package
+func (f *file) UsesEnums() bool {
+	for _, m := range f.Messages {
+		for _, fd := range m.Fields {
+			if strings.HasPrefix(fd.ElemType, f.ProtoPackage+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IngesterImports returns the import lines of the entity structs file, with empty lines between groups
+func (f *file) IngesterImports() []string {
+	var imports []string
+	if f.usesKind(kindBytes, kindRepeatedScalar) {
+		imports = append(imports, `"slices"`)
+	}
+	timestamps := f.UsesTimestamps() || f.EntityTimestamp != nil
+	if timestamps {
+		imports = append(imports, `"time"`)
+	}
+	if len(imports) > 0 {
+		imports = append(imports, "")
+	}
+	imports = append(imports, `"google.golang.org/protobuf/proto"`)
+	if timestamps {
+		imports = append(imports, `"google.golang.org/protobuf/types/known/timestamppb"`)
+	}
+	return append(imports, "", f.protoImport())
+}
+
+// BuilderImports returns the import lines of the builders file, with empty lines between groups
+func (f *file) BuilderImports() []string {
+	var imports []string
+	entities := &file{ProtoPackage: f.ProtoPackage}
+	for _, e := range f.Entities {
+		entities.Messages = append(entities.Messages, e.Message)
+	}
+
+	if entities.usesKind(kindBytes, kindRepeatedScalar, kindRepeatedMessage) {
+		imports = append(imports, `"slices"`)
+	}
+	if entities.UsesTimestamps() {
+		imports = append(imports, `"time"`)
+	}
+	if entities.UsesEnums() {
+		if len(imports) > 0 {
+			imports = append(imports, "")
+		}
+		imports = append(imports, f.protoImport())
+	}
+	return imports
+}
+
+// RefMessages returns the messages with default values built or referenced by their ID by the builders, sorted by name
+func (f *file) RefMessages() []*message {
+	var refs []*message
+	for _, m := range f.Messages {
+		if len(m.Defaults) > 0 && (m.Entity != nil && m.ID != nil || f.referenced(m)) {
+			refs = append(refs, m)
+		}
+	}
+	return refs
+}
+
+// referenced reports whether a field of an entity references the message
+func (f *file) referenced(m *message) bool {
+	for _, e := range f.Entities {
+		for _, fd := range e.Message.Fields {
+			if fd.Message == m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// protoImport returns the import line of the Go package of the proto messages
+func (f *file) protoImport() string {
+	if path.Base(f.ProtoImportPath) != f.ProtoPackage {
+		return fmt.Sprintf("%s %q", f.ProtoPackage, f.ProtoImportPath)
+	}
+	return fmt.Sprintf("%q", f.ProtoImportPath)
+}
+
+// builder builds the model of the generated files from the proto descriptors
+type builder struct {
+	f *file
+
+	// proto package of the entity messages, messages of other packages are not supported but timestamps
+	protoPackage protoreflect.FullName
+
+	// messages by full name
+	messages map[protoreflect.FullName]*message
+}
+
+// newFile builds the model of the generated files from the message wrapping the entities in a oneof
+func newFile(pkg string, entityMessage protoreflect.MessageDescriptor) (*file, error) {
+	importPath, protoPackage, err := goPackage(entityMessage.ParentFile())
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		f: &file{
+			Package:         pkg,
+			ProtoPackage:    protoPackage,
+			ProtoImportPath: importPath,
+			EntityMessage:   goName(entityMessage),
+		},
+		protoPackage: entityMessage.ParentFile().Package(),
+		messages:     make(map[protoreflect.FullName]*message),
+	}
+
+	var entityOneof protoreflect.OneofDescriptor
+	for i := 0; i < entityMessage.Oneofs().Len(); i++ {
+		if o := entityMessage.Oneofs().Get(i); !o.IsSynthetic() {
+			entityOneof = o
+			break
+		}
+	}
+	if entityOneof == nil {
+		return nil, fmt.Errorf("%s has no oneof of entities", entityMessage.FullName())
+	}
+	b.f.EntityOneof = goCamelCase(string(entityOneof.Name()))
+
+	for i := 0; i < entityMessage.Fields().Len(); i++ {
+		fd := entityMessage.Fields().Get(i)
+		if fd.Message() != nil && fd.Message().FullName() == timestampName && fd.Cardinality() != protoreflect.Repeated &&
+			(fd.ContainingOneof() == nil || fd.ContainingOneof().IsSynthetic()) {
+			b.f.EntityTimestamp = &field{GoName: goCamelCase(string(fd.Name())), Tag: string(fd.Name())}
+			break
+		}
+	}
+
+	for i := 0; i < entityOneof.Fields().Len(); i++ {
+		fd := entityOneof.Fields().Get(i)
+		if fd.Kind() != protoreflect.MessageKind {
+			return nil, fmt.Errorf("%s: entities must be messages", fd.FullName())
+		}
+
+		m, err := b.message(fd.Message())
+		if err != nil {
+			return nil, err
+		}
+		if m.Entity != nil {
+			return nil, fmt.Errorf("%s: %s is already the entity %s", fd.FullName(), m.Name, m.Entity.TypeName)
+		}
+
+		m.Entity = &entity{
+			TypeName: string(fd.Name()),
+			GoName:   goCamelCase(string(fd.Name())),
+			Wrapper:  goName(entityMessage) + "_" + goCamelCase(string(fd.Name())),
+			Message:  m,
+		}
+		b.f.Entities = append(b.f.Entities, m.Entity)
+	}
+
+	for _, m := range b.messages {
+		b.f.Messages = append(b.f.Messages, m)
+	}
+	sort.Slice(b.f.Messages, func(i, j int) bool {
+		return b.f.Messages[i].Name < b.f.Messages[j].Name
+	})
+
+	return b.f, nil
+}
+
+// message returns the model of a message, building it and the messages it references on first use
+func (b *builder) message(md protoreflect.MessageDescriptor) (*message, error) {
+	if m, ok := b.messages[md.FullName()]; ok {
+		return m, nil
+	}
+	if md.ParentFile().Package() != b.protoPackage {
+		return nil, fmt.Errorf("%s: messages of other packages are not supported", md.FullName())
+	}
+
+	m := &message{Name: goName(md)}
+	b.messages[md.FullName()] = m
+
+	oneofs := make(map[protoreflect.FullName]*oneof)
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		f, err := b.field(fd)
+		if err != nil {
+			return nil, err
+		}
+		f.Owner = m.Name
+
+		if f.Kind == kindOneofMember {
+			od := fd.ContainingOneof()
+			o, ok := oneofs[od.FullName()]
+			if !ok {
+				o = &oneof{GoName: goCamelCase(string(od.Name()))}
+				oneofs[od.FullName()] = o
+				m.Oneofs = append(m.Oneofs, o)
+			}
+			o.Members = append(o.Members, f)
+		}
+
+		m.Fields = append(m.Fields, f)
+	}
+
+	for _, n := range identifyingFieldNames {
+		if fd := fields.ByName(n); fd != nil && fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated {
+			for _, f := range m.Fields {
+				if f.Tag == string(n) {
+					m.ID = f
+				}
+			}
+			break
+		}
+	}
+
+	if m.ID != nil {
+		m.Defaults = refDefaults(md, m)
+	}
+
+	return m, nil
+}
+
+// refDefaults returns the required string fields of the message set to default values when it is referenced by its
+// ID, slugs default to the slug of the ID and other fields to their refDefaultValues accepted by the validation rules
+func refDefaults(md protoreflect.MessageDescriptor, m *message) []*field {
+	var defaults []*field
+	for i, f := range m.Fields {
+		if !f.Required || f == m.ID || f.Kind != kindScalar || f.ElemType != "string" {
+			continue
+		}
+
+		fd := md.Fields().Get(i)
+		if fd.Name() == "slug" {
+			f.Default = fmt.Sprintf("Slugify(%s)", m.ID.Param)
+		} else if v, ok := refDefaultValues[fd.Name()]; ok && validString(fd, v) {
+			f.Default = strconv.Quote(v)
+		} else {
+			continue
+		}
+		defaults = append(defaults, f)
+	}
+	return defaults
+}
+
+// field returns the model of a field
+func (b *builder) field(fd protoreflect.FieldDescriptor) (*field, error) {
+	f := &field{
+		GoName:   goCamelCase(string(fd.Name())),
+		Tag:      string(fd.Name()),
+		Required: requiredField(fd),
+		Param:    identifier(fd.JSONName()),
+	}
+
+	if fd.IsMap() {
+		return nil, fmt.Errorf("%s: map fields are not supported", fd.FullName())
+	}
+
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		if fd.Message().FullName() == timestampName {
+			if fd.Cardinality() == protoreflect.Repeated || fd.ContainingOneof() != nil && !fd.ContainingOneof().IsSynthetic() {
+				return nil, fmt.Errorf("%s: repeated and oneof timestamps are not supported", fd.FullName())
+			}
+			f.Kind = kindTimestamp
+			f.Type = "*time.Time"
+			f.ElemType = "time.Time"
+			f.ProtoType = "*timestamppb.Timestamp"
+			return f, nil
+		}
+
+		m, err := b.message(fd.Message())
+		if err != nil {
+			return nil, err
+		}
+		f.Message = m
+
+		switch od := fd.ContainingOneof(); {
+		case fd.Cardinality() == protoreflect.Repeated:
+			f.Kind = kindRepeatedMessage
+			f.Type = "[]*" + m.Name
+			f.ProtoType = "[]*" + b.f.ProtoPackage + "." + m.Name
+		case od != nil && od.Fields().Len() == 1:
+			f.Kind = kindOneofMessage
+			f.GoName = goCamelCase(string(od.Name()))
+			f.Tag = string(od.Name())
+			f.Oneof = f.GoName
+			f.MemberGoName = goCamelCase(string(fd.Name()))
+			f.OneofWrapper = goName(fd.ContainingMessage()) + "_" + f.MemberGoName
+			f.Type = "*" + m.Name
+			f.ProtoType = "*" + b.f.ProtoPackage + "." + f.OneofWrapper
+		case od != nil:
+			f.Kind = kindOneofMember
+			f.Oneof = goCamelCase(string(od.Name()))
+			f.MemberGoName = f.GoName
+			f.OneofWrapper = goName(fd.ContainingMessage()) + "_" + f.GoName
+			f.Type = "*" + m.Name
+			f.ProtoType = "*" + b.f.ProtoPackage + "." + m.Name
+		default:
+			f.Kind = kindMessage
+			f.Type = "*" + m.Name
+			f.ProtoType = "*" + b.f.ProtoPackage + "." + m.Name
+		}
+		return f, nil
+	}
+
+	if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+		return nil, fmt.Errorf("%s: scalar oneof members are not supported", fd.FullName())
+	}
+
+	elemType, zero, err := b.scalarType(fd)
+	if err != nil {
+		return nil, err
+	}
+	f.ElemType = elemType
+	f.Zero = zero
+
+	switch {
+	case fd.Cardinality() == protoreflect.Repeated:
+		f.Kind = kindRepeatedScalar
+		f.Type = "[]" + elemType
+		f.ProtoType = f.Type
+	case fd.Kind() == protoreflect.BytesKind:
+		f.Kind = kindBytes
+		f.Type = elemType
+		f.ProtoType = elemType
+	case fd.HasPresence():
+		f.Kind = kindOptionalScalar
+		f.Type = "*" + elemType
+		f.ProtoType = f.Type
+	default:
+		f.Kind = kindScalar
+		f.Type = "*" + elemType
+		f.ProtoType = elemType
+	}
+	return f, nil
+}
+
+// scalarType returns the Go type and zero value of a scalar field
+func (b *builder) scalarType(fd protoreflect.FieldDescriptor) (string, string, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return "bool", "false", nil
+	case protoreflect.StringKind:
+		return "string", `""`, nil
+	case protoreflect.BytesKind:
+		return "[]byte", "nil", nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "int32", "0", nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "int64", "0", nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "uint32", "0", nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "uint64", "0", nil
+	case protoreflect.FloatKind:
+		return "float32", "0", nil
+	case protoreflect.DoubleKind:
+		return "float64", "0", nil
+	case protoreflect.EnumKind:
+		if fd.Enum().ParentFile().Package() != b.protoPackage {
+			return "", "", fmt.Errorf("%s: enums of other packages are not supported", fd.FullName())
+		}
+		return b.f.ProtoPackage + "." + goName(fd.Enum()), "0", nil
+	default:
+		return "", "", fmt.Errorf("%s: unsupported field kind %s", fd.FullName(), fd.Kind())
+	}
+}
+
+// requiredField reports whether the validation rules of the field require it to be set, rejecting its zero value
+func requiredField(fd protoreflect.FieldDescriptor) bool {
+	rules, ok := proto.GetExtension(fd.Options(), validate.E_Rules).(*validate.FieldRules)
+	if !ok || rules == nil {
+		return false
+	}
+
+	if fd.Kind() == protoreflect.MessageKind {
+		return rules.GetMessage().GetRequired() || rules.GetAny().GetRequired()
+	}
+
+	// rules of optional fields only apply once they are set
+	if fd.HasPresence() {
+		return false
+	}
+
+	return !validString(fd, "")
+}
+
+// validString reports whether the string validation rules of the field, if any, accept the value
+func validString(fd protoreflect.FieldDescriptor, v string) bool {
+	rules, ok := proto.GetExtension(fd.Options(), validate.E_Rules).(*validate.FieldRules)
+	if !ok || rules == nil {
+		return true
+	}
+
+	s := rules.GetString_()
+	if s == nil || v == "" && s.GetIgnoreEmpty() {
+		return true
+	}
+	n := uint64(utf8.RuneCountInString(v))
+	switch {
+	case s.Len != nil && n != s.GetLen(), n < s.GetMinLen(), s.MaxLen != nil && n > s.GetMaxLen():
+		return false
+	case len(s.GetIn()) > 0 && !slices.Contains(s.GetIn(), v), slices.Contains(s.GetNotIn(), v):
+		return false
+	case s.Pattern != nil:
+		matched, err := regexp.MatchString(s.GetPattern(), v)
+		return err == nil && matched
+	default:
+		return true
+	}
+}
+
+// goPackage returns the import path and name of the Go package of the proto file, from its go_package option
+func goPackage(fd protoreflect.FileDescriptor) (string, string, error) {
+	opts, _ := fd.Options().(*descriptorpb.FileOptions)
+	goPkg := opts.GetGoPackage()
+	if goPkg == "" {
+		return "", "", fmt.Errorf("%s: go_package option is required", fd.Path())
+	}
+
+	importPath, name, found := strings.Cut(goPkg, ";")
+	if !found {
+		name = path.Base(importPath)
+	}
+	return importPath, name, nil
+}
+
+// goName returns the Go name of a message or enum, nested ones are prefixed with their parents as by protoc-gen-go
+func goName(d protoreflect.Descriptor) string {
+	name := goCamelCase(string(d.Name()))
+	if parent, ok := d.Parent().(protoreflect.MessageDescriptor); ok {
+		return goName(parent) + "_" + name
+	}
+	return name
+}
+
+// goCamelCase converts a proto name to the Go name protoc-gen-go generates, e.g. ip_address to IpAddress
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_' && i == 0:
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// skip the underscore, the next letter is capitalized
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// identifier returns the name, suffixed with Value if it is a Go keyword
+func identifier(name string) string {
+	if token.IsKeyword(name) {
+		return name + "Value"
+	}
+	return name
+}
+
+// lowerFirst lowercases the first letter of the name
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}